@@ -0,0 +1,244 @@
+package flashbot
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ERC20ABI holds the events of the ERC-20 token standard.
+const ERC20ABI = `[
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Approval","anonymous":false,"inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+// ERC721ABI holds the events of the ERC-721 non-fungible token standard.
+const ERC721ABI = `[
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"Approval","anonymous":false,"inputs":[{"name":"owner","type":"address","indexed":true},{"name":"approved","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"ApprovalForAll","anonymous":false,"inputs":[{"name":"owner","type":"address","indexed":true},{"name":"operator","type":"address","indexed":true},{"name":"approved","type":"bool","indexed":false}]}
+]`
+
+// ERC1155ABI holds the transfer events of the ERC-1155 multi token standard.
+const ERC1155ABI = `[
+	{"type":"event","name":"TransferSingle","anonymous":false,"inputs":[{"name":"operator","type":"address","indexed":true},{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"id","type":"uint256","indexed":false},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"TransferBatch","anonymous":false,"inputs":[{"name":"operator","type":"address","indexed":true},{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"ids","type":"uint256[]","indexed":false},{"name":"values","type":"uint256[]","indexed":false}]}
+]`
+
+// WETHABI holds the events WETH9 emits on top of ERC-20's.
+const WETHABI = `[
+	{"type":"event","name":"Deposit","anonymous":false,"inputs":[{"name":"dst","type":"address","indexed":true},{"name":"wad","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Withdrawal","anonymous":false,"inputs":[{"name":"src","type":"address","indexed":true},{"name":"wad","type":"uint256","indexed":false}]}
+]`
+
+// ABIRegistry decodes logs with the events of registered ABIs, and revert data with their custom errors.
+// ABIs registered for a contract address take precedence over those registered for every address when
+// decoding logs. Events sharing a signature, such as the ERC-20 and ERC-721 Transfer, are told apart by
+// their number of indexed arguments.
+// An ABIRegistry is safe for concurrent use.
+type ABIRegistry struct {
+	mu        sync.RWMutex
+	global    map[common.Hash][]abi.Event
+	contracts map[common.Address]map[common.Hash][]abi.Event
+	// errors holds the custom errors of every registered ABI by selector, reverts carry no emitter address.
+	errors map[[4]byte][]abi.Error
+}
+
+// DecodedEvent is a log decoded with a registered ABI.
+type DecodedEvent struct {
+	// TxIndex is the index of the transaction in the simulated bundle, LogIndex the index of the log in it.
+	TxIndex  int
+	LogIndex int
+	Address  common.Address
+	// Name and Signature are empty when no registered ABI matches the log.
+	Name      string
+	Signature string
+	// Args maps argument names to their Go values, such as common.Address and *big.Int.
+	// Indexed arguments of dynamic types hold the keccak256 hash of the value.
+	Args map[string]interface{}
+	// Log is the raw log.
+	Log LogEntry
+}
+
+// NewABIRegistry returns an empty registry.
+func NewABIRegistry() *ABIRegistry {
+	return &ABIRegistry{
+		global:    make(map[common.Hash][]abi.Event),
+		contracts: make(map[common.Address]map[common.Hash][]abi.Event),
+		errors:    make(map[[4]byte][]abi.Error),
+	}
+}
+
+// DefaultABIRegistry returns a registry seeded with the ERC-20, ERC-721, ERC-1155 and WETH events.
+func DefaultABIRegistry() *ABIRegistry {
+	r := NewABIRegistry()
+	for _, def := range []string{ERC20ABI, ERC721ABI, ERC1155ABI, WETHABI} {
+		if err := r.RegisterJSON(def); err != nil {
+			panic(fmt.Sprintf("invalid built-in ABI: %v", err))
+		}
+	}
+	return r
+}
+
+// Register adds the events and errors of a to the registry, for logs of any address.
+func (r *ABIRegistry) Register(a *abi.ABI) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	addEvents(r.global, a)
+	r.addErrors(a)
+}
+
+// RegisterContract adds the events of a for logs emitted by addr only. Its errors decode any revert.
+func (r *ABIRegistry) RegisterContract(addr common.Address, a *abi.ABI) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.contracts[addr] == nil {
+		r.contracts[addr] = make(map[common.Hash][]abi.Event)
+	}
+	addEvents(r.contracts[addr], a)
+	r.addErrors(a)
+}
+
+// RegisterJSON parses a JSON ABI and adds its events for logs of any address.
+func (r *ABIRegistry) RegisterJSON(def string) error {
+	a, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		return fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	r.Register(&a)
+	return nil
+}
+
+// RegisterMetaData adds the events of an abigen binding's metadata, such as erc20ex.Erc20exMetaData.
+// With addresses, the events only decode logs emitted by those contracts.
+func (r *ABIRegistry) RegisterMetaData(md *bind.MetaData, addresses ...common.Address) error {
+	a, err := md.GetAbi()
+	if err != nil {
+		return fmt.Errorf("failed to load ABI: %w", err)
+	}
+	if len(addresses) == 0 {
+		r.Register(a)
+		return nil
+	}
+	for _, addr := range addresses {
+		r.RegisterContract(addr, a)
+	}
+	return nil
+}
+
+// DecodeLog decodes a single log. An error is returned when no registered event matches it.
+func (r *ABIRegistry) DecodeLog(entry LogEntry) (*DecodedEvent, error) {
+	addr := common.HexToAddress(entry.Address)
+	topics := make([]common.Hash, len(entry.Topics))
+	for i, topic := range entry.Topics {
+		topics[i] = common.HexToHash(topic)
+	}
+	data, err := hexutil.Decode(entry.Data)
+	if err != nil && entry.Data != "" && entry.Data != "0x" {
+		return nil, fmt.Errorf("invalid log data: %w", err)
+	}
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("anonymous log of %s cannot be decoded", addr.Hex())
+	}
+
+	r.mu.RLock()
+	candidates := append(append([]abi.Event{}, r.contracts[addr][topics[0]]...), r.global[topics[0]]...)
+	r.mu.RUnlock()
+
+	for _, event := range candidates {
+		indexed := indexedArguments(event.Inputs)
+		if len(indexed) != len(topics)-1 {
+			continue
+		}
+		args := make(map[string]interface{})
+		if err := event.Inputs.NonIndexed().UnpackIntoMap(args, data); err != nil {
+			continue
+		}
+		if err := abi.ParseTopicsIntoMap(args, indexed, topics[1:]); err != nil {
+			continue
+		}
+		return &DecodedEvent{
+			Address:   addr,
+			Name:      event.Name,
+			Signature: event.Sig,
+			Args:      args,
+			Log:       entry,
+		}, nil
+	}
+	return nil, fmt.Errorf("no registered event matches topic %s of %s", topics[0].Hex(), addr.Hex())
+}
+
+// DecodeSimulation decodes every log of a simulation, in order. Logs no registered event matches are
+// returned with an empty Name, so callers see every log of the bundle. The logs of a nested bundle are
+// attributed to the body item holding it, numbered after the item's own logs.
+func (r *ABIRegistry) DecodeSimulation(res *SimulateResponse) []DecodedEvent {
+	var events []DecodedEvent
+	for txIndex := range res.Logs {
+		logIndex := 0
+		res.Logs[txIndex].walk(func(txLogs *TxLogResult) {
+			for _, entry := range txLogs.TxLogs {
+				event, err := r.DecodeLog(entry)
+				if err != nil {
+					event = &DecodedEvent{Address: common.HexToAddress(entry.Address), Log: entry}
+				}
+				event.TxIndex = txIndex
+				event.LogIndex = logIndex
+				logIndex++
+				events = append(events, *event)
+			}
+		})
+	}
+	return events
+}
+
+// AddressArg returns the address argument name.
+func (e *DecodedEvent) AddressArg(name string) (common.Address, bool) {
+	addr, ok := e.Args[name].(common.Address)
+	return addr, ok
+}
+
+// BigIntArg returns the integer argument name.
+func (e *DecodedEvent) BigIntArg(name string) (*big.Int, bool) {
+	v, ok := e.Args[name].(*big.Int)
+	return v, ok
+}
+
+func addEvents(events map[common.Hash][]abi.Event, a *abi.ABI) {
+	for _, event := range a.Events {
+		if event.Anonymous {
+			continue
+		}
+		events[event.ID] = append(events[event.ID], event)
+	}
+}
+
+// addErrors indexes the custom errors of a by selector. r.mu must be held.
+func (r *ABIRegistry) addErrors(a *abi.ABI) {
+next:
+	for _, e := range a.Errors {
+		var selector [4]byte
+		copy(selector[:], e.ID[:4])
+		for _, known := range r.errors[selector] {
+			if known.Sig == e.Sig {
+				continue next
+			}
+		}
+		r.errors[selector] = append(r.errors[selector], e)
+	}
+}
+
+func indexedArguments(args abi.Arguments) abi.Arguments {
+	var indexed abi.Arguments
+	for _, arg := range args {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return indexed
+}
@@ -0,0 +1,75 @@
+package flashbot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/harpy-wings/flashbot/testutils/erc20ex"
+	"github.com/stretchr/testify/require"
+)
+
+func TestABIRegistry(t *testing.T) {
+	transfer := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")).Hex()
+	deposit := crypto.Keccak256Hash([]byte("Deposit(address,uint256)")).Hex()
+	token := common.HexToAddress("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	word := func(v int64) string { return common.BigToHash(big.NewInt(v)).Hex() }
+
+	res := &SimulateResponse{Logs: []TxLogResult{
+		{TxLogs: []LogEntry{
+			// ERC-20 transfer of 5, the amount is in the data.
+			{Address: token.Hex(), Topics: []string{transfer, common.BytesToHash(from.Bytes()).Hex(), common.BytesToHash(to.Bytes()).Hex()}, Data: word(5)},
+			// ERC-721 transfer of token 7, every argument is indexed.
+			{Address: token.Hex(), Topics: []string{transfer, common.BytesToHash(from.Bytes()).Hex(), common.BytesToHash(to.Bytes()).Hex(), word(7)}, Data: "0x"},
+		}},
+		{TxLogs: []LogEntry{
+			{Address: token.Hex(), Topics: []string{deposit, common.BytesToHash(to.Bytes()).Hex()}, Data: word(9)},
+			{Address: token.Hex(), Topics: []string{word(1)}, Data: "0x"},
+		}},
+	}}
+
+	events := DefaultABIRegistry().DecodeSimulation(res)
+	require.Len(t, events, 4)
+
+	require.Equal(t, "Transfer", events[0].Name)
+	sender, ok := events[0].AddressArg("from")
+	require.True(t, ok)
+	require.Equal(t, from, sender)
+	value, ok := events[0].BigIntArg("value")
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(5), value)
+
+	tokenID, ok := events[1].BigIntArg("tokenId")
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(7), tokenID)
+
+	require.Equal(t, "Deposit", events[2].Name)
+	require.Equal(t, 1, events[2].TxIndex)
+	require.Equal(t, big.NewInt(9), events[2].Args["wad"])
+
+	require.Empty(t, events[3].Name)
+	require.Equal(t, 1, events[3].LogIndex)
+
+	// Logs of a nested bundle belong to the transaction of the outer bundle that carries it.
+	nested := &SimulateResponse{Logs: []TxLogResult{
+		{},
+		{BundleLogs: []TxLogResult{res.Logs[0], res.Logs[1]}},
+	}}
+	events = DefaultABIRegistry().DecodeSimulation(nested)
+	require.Len(t, events, 4)
+	require.Equal(t, 1, events[2].TxIndex)
+	require.Equal(t, 1, events[3].TxIndex)
+	require.Equal(t, 3, events[3].LogIndex)
+
+	// Events of a binding registered for a contract take precedence over the built-in ones.
+	r := NewABIRegistry()
+	require.NoError(t, r.RegisterMetaData(erc20ex.Erc20exMetaData, token))
+	event, err := r.DecodeLog(res.Logs[0].TxLogs[0])
+	require.NoError(t, err)
+	require.Equal(t, "Transfer", event.Name)
+	_, err = r.DecodeLog(LogEntry{Address: from.Hex(), Topics: res.Logs[0].TxLogs[0].Topics, Data: word(5)})
+	require.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package flashbot
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// AssetKind is the standard an asset follows.
+type AssetKind string
+
+const (
+	AssetKindETH     AssetKind = "eth"
+	AssetKindERC20   AssetKind = "erc20"
+	AssetKindERC721  AssetKind = "erc721"
+	AssetKindERC1155 AssetKind = "erc1155"
+)
+
+// Asset identifies what a balance is held in. Token is zero for ETH and TokenID, in decimal, is only set
+// for ERC-721 and ERC-1155 tokens. WETH is an ERC-20 asset.
+type Asset struct {
+	Kind    AssetKind
+	Token   common.Address
+	TokenID string
+}
+
+// ETHAsset is the asset of native ETH balances.
+var ETHAsset = Asset{Kind: AssetKindETH}
+
+// ERC20Asset returns the asset of an ERC-20 token, such as WETH.
+func ERC20Asset(token common.Address) Asset {
+	return Asset{Kind: AssetKindERC20, Token: token}
+}
+
+// String describes the asset, as in "erc20 0xC02a...".
+func (a Asset) String() string {
+	switch {
+	case a.Kind == AssetKindETH:
+		return string(a.Kind)
+	case a.TokenID != "":
+		return fmt.Sprintf("%s %s #%s", a.Kind, a.Token.Hex(), a.TokenID)
+	default:
+		return fmt.Sprintf("%s %s", a.Kind, a.Token.Hex())
+	}
+}
+
+// AssetChanges holds the net balance change of every address a bundle touches, per asset.
+type AssetChanges struct {
+	Deltas map[common.Address]map[Asset]*big.Int
+}
+
+// assetEvents decodes the standard token events, independently of the ABIs callers register.
+var assetEvents = DefaultABIRegistry()
+
+// defaultWETH holds the WETH9 contracts of mainnet and Sepolia.
+var defaultWETH = map[common.Address]bool{
+	common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"): true,
+	common.HexToAddress("0xfFf9976782d46CC05630D1f6eBAb18b2324d6B14"): true,
+}
+
+// assetChangesConfig holds the settings of AnalyzeAssetChanges.
+type assetChangesConfig struct {
+	weth map[common.Address]bool
+}
+
+// AssetChangesOption configures AnalyzeAssetChanges.
+type AssetChangesOption func(*assetChangesConfig)
+
+// WithWETH adds contracts whose Deposit and Withdrawal events are WETH mints and burns, for chains other
+// than mainnet and Sepolia or other wrappers following WETH9.
+func WithWETH(tokens ...common.Address) AssetChangesOption {
+	return func(cfg *assetChangesConfig) {
+		for _, token := range tokens {
+			cfg.weth[token] = true
+		}
+	}
+}
+
+// AnalyzeAssetChanges derives balance changes from a bundle and its simulation. It counts:
+//   - the ETH value of every transaction that executed without error, from sender to recipient;
+//   - ERC-20 and ERC-721 Transfer, and ERC-1155 TransferSingle and TransferBatch logs;
+//   - WETH Deposit (WETH minted to dst) and Withdrawal (WETH burned from src, ETH paid to src) logs.
+//
+// Deposit and Withdrawal are only counted for the WETH of mainnet and Sepolia and the contracts given with
+// WithWETH: vaults and bridges emit events of the same signature without minting WETH or paying ETH.
+// ETH moved by internal calls, other than WETH withdrawals, is not visible in logs and is not counted,
+// neither are gas fees (see EstimateBundleCost). Mints and burns show up as changes of the zero address.
+func AnalyzeAssetChanges(bundle *Bundle, res *SimulateResponse, opts ...AssetChangesOption) (*AssetChanges, error) {
+	cfg := assetChangesConfig{weth: make(map[common.Address]bool, len(defaultWETH))}
+	for token := range defaultWETH {
+		cfg.weth[token] = true
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	changes := &AssetChanges{Deltas: make(map[common.Address]map[Asset]*big.Int)}
+	for i, tx := range bundle.Transactions {
+		if tx.Value().Sign() == 0 || tx.To() == nil || !txExecuted(res, i) {
+			continue
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+		}
+		changes.move(ETHAsset, sender, *tx.To(), tx.Value())
+	}
+
+	for _, event := range assetEvents.DecodeSimulation(res) {
+		from, _ := event.AddressArg("from")
+		to, _ := event.AddressArg("to")
+		switch event.Name {
+		case "Transfer":
+			if value, ok := event.BigIntArg("value"); ok {
+				changes.move(ERC20Asset(event.Address), from, to, value)
+			} else if id, ok := event.BigIntArg("tokenId"); ok {
+				asset := Asset{Kind: AssetKindERC721, Token: event.Address, TokenID: id.String()}
+				changes.move(asset, from, to, big.NewInt(1))
+			}
+		case "TransferSingle":
+			id, _ := event.BigIntArg("id")
+			value, _ := event.BigIntArg("value")
+			changes.move(Asset{Kind: AssetKindERC1155, Token: event.Address, TokenID: id.String()}, from, to, value)
+		case "TransferBatch":
+			ids, _ := event.Args["ids"].([]*big.Int)
+			values, _ := event.Args["values"].([]*big.Int)
+			if len(ids) != len(values) {
+				return nil, fmt.Errorf("transaction %d log %d: %d ids for %d values", event.TxIndex, event.LogIndex, len(ids), len(values))
+			}
+			for j := range ids {
+				changes.move(Asset{Kind: AssetKindERC1155, Token: event.Address, TokenID: ids[j].String()}, from, to, values[j])
+			}
+		case "Deposit":
+			if !cfg.weth[event.Address] {
+				continue
+			}
+			dst, _ := event.AddressArg("dst")
+			wad, _ := event.BigIntArg("wad")
+			changes.move(ERC20Asset(event.Address), common.Address{}, dst, wad)
+		case "Withdrawal":
+			if !cfg.weth[event.Address] {
+				continue
+			}
+			src, _ := event.AddressArg("src")
+			wad, _ := event.BigIntArg("wad")
+			changes.move(ERC20Asset(event.Address), src, common.Address{}, wad)
+			changes.move(ETHAsset, event.Address, src, wad)
+		}
+	}
+	return changes, nil
+}
+
+// Delta returns the net change of addr in asset, zero when it did not change.
+func (c *AssetChanges) Delta(addr common.Address, asset Asset) *big.Int {
+	if delta, ok := c.Deltas[addr][asset]; ok {
+		return new(big.Int).Set(delta)
+	}
+	return new(big.Int)
+}
+
+// ETH returns the net ETH change of addr.
+func (c *AssetChanges) ETH(addr common.Address) *big.Int {
+	return c.Delta(addr, ETHAsset)
+}
+
+// Token returns the net change of addr in an ERC-20 token.
+func (c *AssetChanges) Token(addr common.Address, token common.Address) *big.Int {
+	return c.Delta(addr, ERC20Asset(token))
+}
+
+// move transfers amount of asset from one address to another.
+func (c *AssetChanges) move(asset Asset, from, to common.Address, amount *big.Int) {
+	if amount == nil || amount.Sign() == 0 || from == to {
+		return
+	}
+	c.add(from, asset, new(big.Int).Neg(amount))
+	c.add(to, asset, amount)
+}
+
+func (c *AssetChanges) add(addr common.Address, asset Asset, amount *big.Int) {
+	if c.Deltas[addr] == nil {
+		c.Deltas[addr] = make(map[Asset]*big.Int)
+	}
+	delta, ok := c.Deltas[addr][asset]
+	if !ok {
+		delta = new(big.Int)
+		c.Deltas[addr][asset] = delta
+	}
+	delta.Add(delta, amount)
+	if delta.Sign() == 0 {
+		delete(c.Deltas[addr], asset)
+		if len(c.Deltas[addr]) == 0 {
+			delete(c.Deltas, addr)
+		}
+	}
+}
+
+// txExecuted reports whether the simulation executed transaction i without error.
+func txExecuted(res *SimulateResponse, i int) bool {
+	if i < len(res.Logs) {
+		return res.Logs[i].Error == ""
+	}
+	return res.Success
+}
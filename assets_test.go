@@ -0,0 +1,84 @@
+package flashbot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeAssetChanges(t *testing.T) {
+	topic := func(sig string) string { return crypto.Keccak256Hash([]byte(sig)).Hex() }
+	addrTopic := func(addr common.Address) string { return common.BytesToHash(addr.Bytes()).Hex() }
+	word := func(v int64) string { return common.BigToHash(big.NewInt(v)).Hex() }
+
+	tx, sender := newTestTx(t, 0) // sends 1 wei
+	recipient := *tx.To()
+	weth := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	token := common.HexToAddress("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238")
+	nft := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	multi := common.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	transfer := topic("Transfer(address,address,uint256)")
+	batchData := hexutil.Encode(common.FromHex(
+		word(64) + word(160)[2:] + // offsets of ids and values
+			word(2)[2:] + word(1)[2:] + word(2)[2:] + // ids [1, 2]
+			word(2)[2:] + word(10)[2:] + word(20)[2:], // values [10, 20]
+	))
+	res := &SimulateResponse{Success: true, Logs: []TxLogResult{{TxLogs: []LogEntry{
+		{Address: weth.Hex(), Topics: []string{topic("Deposit(address,uint256)"), addrTopic(sender)}, Data: word(100)},
+		{Address: weth.Hex(), Topics: []string{transfer, addrTopic(sender), addrTopic(recipient)}, Data: word(40)},
+		{Address: weth.Hex(), Topics: []string{topic("Withdrawal(address,uint256)"), addrTopic(recipient)}, Data: word(15)},
+		{Address: token.Hex(), Topics: []string{transfer, addrTopic(recipient), addrTopic(sender)}, Data: word(7)},
+		{Address: nft.Hex(), Topics: []string{transfer, addrTopic(recipient), addrTopic(sender), word(9)}, Data: "0x"},
+		{Address: multi.Hex(), Topics: []string{topic("TransferBatch(address,address,address,uint256[],uint256[])"), addrTopic(sender), addrTopic(sender), addrTopic(recipient)}, Data: batchData},
+	}}}}
+
+	changes, err := AnalyzeAssetChanges(&Bundle{Transactions: []*types.Transaction{tx}}, res)
+	require.NoError(t, err)
+
+	require.Equal(t, big.NewInt(-1), changes.ETH(sender))
+	require.Equal(t, big.NewInt(16), changes.ETH(recipient))
+	require.Equal(t, big.NewInt(-15), changes.ETH(weth))
+	require.Equal(t, big.NewInt(60), changes.Token(sender, weth))
+	require.Equal(t, big.NewInt(25), changes.Token(recipient, weth))
+	require.Equal(t, big.NewInt(-85), changes.Token(common.Address{}, weth))
+	require.Equal(t, big.NewInt(7), changes.Token(sender, token))
+	require.Equal(t, big.NewInt(1), changes.Delta(sender, Asset{Kind: AssetKindERC721, Token: nft, TokenID: "9"}))
+	require.Equal(t, big.NewInt(20), changes.Delta(recipient, Asset{Kind: AssetKindERC1155, Token: multi, TokenID: "2"}))
+	require.Equal(t, big.NewInt(-10), changes.Delta(sender, Asset{Kind: AssetKindERC1155, Token: multi, TokenID: "1"}))
+
+	// A vault emitting Deposit and Withdrawal mints no WETH and pays no ETH, unless it is declared as WETH.
+	vault := common.HexToAddress("0x5555555555555555555555555555555555555555")
+	vaultRes := &SimulateResponse{Success: true, Logs: []TxLogResult{{TxLogs: []LogEntry{
+		{Address: vault.Hex(), Topics: []string{topic("Deposit(address,uint256)"), addrTopic(sender)}, Data: word(100)},
+		{Address: vault.Hex(), Topics: []string{topic("Withdrawal(address,uint256)"), addrTopic(recipient)}, Data: word(15)},
+	}}}}
+	changes, err = AnalyzeAssetChanges(&Bundle{Transactions: []*types.Transaction{tx}}, vaultRes)
+	require.NoError(t, err)
+	require.Zero(t, changes.Token(sender, vault).Sign())
+	require.Equal(t, big.NewInt(1), changes.ETH(recipient))
+	changes, err = AnalyzeAssetChanges(&Bundle{Transactions: []*types.Transaction{tx}}, vaultRes, WithWETH(vault))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), changes.Token(sender, vault))
+	require.Equal(t, big.NewInt(16), changes.ETH(recipient))
+
+	// Transfers of a nested bundle count as well.
+	nestedRes := &SimulateResponse{Success: true, Logs: []TxLogResult{{BundleLogs: []TxLogResult{{TxLogs: []LogEntry{
+		{Address: token.Hex(), Topics: []string{transfer, addrTopic(recipient), addrTopic(sender)}, Data: word(7)},
+	}}}}}}
+	changes, err = AnalyzeAssetChanges(&Bundle{}, nestedRes)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(7), changes.Token(sender, token))
+
+	// A reverted transaction moves no ETH.
+	res.Logs[0].Error = "execution reverted"
+	res.Logs[0].TxLogs = nil
+	changes, err = AnalyzeAssetChanges(&Bundle{Transactions: []*types.Transaction{tx}}, res)
+	require.NoError(t, err)
+	require.Empty(t, changes.Deltas)
+}
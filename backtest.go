@@ -0,0 +1,241 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BacktestMode selects where Backtest simulates the bundle.
+type BacktestMode string
+
+const (
+	// BacktestModeLocal runs the bundle on a LocalSimulator, which needs a node serving the historical state.
+	// Nonces are not checked, so a bundle signed for one block replays on any other.
+	BacktestModeLocal BacktestMode = "local"
+	// BacktestModeRelay runs the bundle with the relay's eth_callBundle on the state of the previous block.
+	// The relay checks nonces and only serves recent state.
+	BacktestModeRelay BacktestMode = "relay"
+)
+
+// BacktestBlock is the outcome of the bundle in one past block.
+type BacktestBlock struct {
+	Block   uint64
+	Success bool
+	GasUsed uint64
+	// Profit is what the bundle paid the block's coinbase, MevGasPrice the profit per unit of gas.
+	Profit      *big.Int
+	MevGasPrice *big.Int
+	// Reverted lists the indices of the transactions that reverted.
+	Reverted []int
+	// Error tells why the bundle failed in this block.
+	Error string
+	// Err is set when the block could not be simulated, for example because the node pruned its state.
+	Err error
+}
+
+// BacktestReport is the outcome of the bundle in every block of a backtest, in block order.
+type BacktestReport struct {
+	Mode      BacktestMode
+	FromBlock uint64
+	ToBlock   uint64
+	Blocks    []BacktestBlock
+	// Succeeded counts the blocks the bundle succeeded in, Profitable those of them with a positive profit.
+	Succeeded  int
+	Profitable int
+	// Best is the successful block with the highest profit, nil when the bundle never succeeded.
+	Best *BacktestBlock
+}
+
+// backtestConfig holds the settings of a single Backtest call.
+type backtestConfig struct {
+	mode      BacktestMode
+	simulator Simulator
+	step      uint64
+}
+
+// BacktestOption configures Backtest.
+type BacktestOption func(*backtestConfig) error
+
+// WithBacktestMode selects where blocks are simulated. Defaults to BacktestModeLocal with an Ethereum client
+// and BacktestModeRelay without one.
+func WithBacktestMode(mode BacktestMode) BacktestOption {
+	return func(cfg *backtestConfig) error {
+		switch mode {
+		case BacktestModeLocal, BacktestModeRelay:
+			cfg.mode = mode
+			return nil
+		default:
+			return fmt.Errorf("unknown backtest mode %q", mode)
+		}
+	}
+}
+
+// WithBacktestSimulator sets the simulator of BacktestModeLocal. Defaults to a LocalSimulator on the client's
+// Ethereum node that skips nonce checks.
+func WithBacktestSimulator(sim Simulator) BacktestOption {
+	return func(cfg *backtestConfig) error {
+		if sim == nil {
+			return fmt.Errorf("simulator cannot be nil")
+		}
+		cfg.simulator = sim
+		cfg.mode = BacktestModeLocal
+		return nil
+	}
+}
+
+// WithBacktestStep simulates every step-th block of the range only. Defaults to 1.
+func WithBacktestStep(step uint64) BacktestOption {
+	return func(cfg *backtestConfig) error {
+		if step == 0 {
+			return fmt.Errorf("step must be positive")
+		}
+		cfg.step = step
+		return nil
+	}
+}
+
+// Backtest replays the bundle in every block from fromBlock to toBlock, inclusive, each time on the state
+// the block was built on, and reports per block whether it succeeded, its gas and its profit.
+// Blocks that cannot be simulated are reported with Err and do not stop the backtest; a cancelled context
+// does, and the report of the blocks done so far is returned with its error.
+func (f *flashbot) Backtest(ctx context.Context, bundle *Bundle, fromBlock, toBlock uint64, opts ...BacktestOption) (*BacktestReport, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.Backtest")
+	defer span.End()
+
+	if len(bundle.Transactions) == 0 {
+		span.SetStatus(codes.Error, "bundle is empty")
+		return nil, fmt.Errorf("bundle cannot be empty")
+	}
+	if fromBlock == 0 || fromBlock > toBlock {
+		span.SetStatus(codes.Error, "invalid block range")
+		return nil, fmt.Errorf("invalid block range %d to %d", fromBlock, toBlock)
+	}
+	cfg := backtestConfig{mode: BacktestModeRelay, step: 1}
+	if f.ethC != nil {
+		cfg.mode = BacktestModeLocal
+	}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+	if cfg.mode == BacktestModeLocal && cfg.simulator == nil {
+		sim, err := NewLocalSimulator(f.ethC, WithLocalSkipNonceChecks())
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
+		cfg.simulator = sim
+	}
+	if f.ethC != nil {
+		head, err := f.ethC.BlockNumber(ctx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to get block number: %w", err)
+		}
+		if toBlock > head {
+			span.SetStatus(codes.Error, "block range ends in the future")
+			return nil, fmt.Errorf("block %d is past the latest block %d", toBlock, head)
+		}
+	}
+
+	report := &BacktestReport{Mode: cfg.mode, FromBlock: fromBlock, ToBlock: toBlock}
+	for block := fromBlock; block <= toBlock; block += cfg.step {
+		if err := ctx.Err(); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return report, err
+		}
+		var result BacktestBlock
+		if cfg.mode == BacktestModeLocal {
+			result = backtestLocal(ctx, cfg.simulator, bundle, block)
+		} else {
+			result = f.backtestRelay(ctx, bundle, block)
+		}
+		report.Blocks = append(report.Blocks, result)
+		if block+cfg.step < block {
+			break // overflow
+		}
+	}
+
+	for i := range report.Blocks {
+		b := &report.Blocks[i]
+		if !b.Success {
+			continue
+		}
+		report.Succeeded++
+		if b.Profit.Sign() > 0 {
+			report.Profitable++
+		}
+		if report.Best == nil || b.Profit.Cmp(report.Best.Profit) > 0 {
+			report.Best = b
+		}
+	}
+	span.SetStatus(codes.Ok, "backtest completed")
+	return report, nil
+}
+
+// backtestLocal simulates the bundle as part of block.
+func backtestLocal(ctx context.Context, sim Simulator, bundle *Bundle, block uint64) BacktestBlock {
+	result := BacktestBlock{Block: block, Profit: new(big.Int), MevGasPrice: new(big.Int)}
+	res, err := sim.Simulate(ctx, bundle, block)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	numbers, err := res.Numbers()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Success = res.Success
+	result.Error = res.Error
+	result.GasUsed = numbers.GasUsed
+	result.Profit = numbers.Profit
+	result.MevGasPrice = numbers.MevGasPrice
+	for i, txResult := range res.Logs {
+		// Transactions without gas used were invalid and never executed.
+		if txResult.Error != "" && txResult.GasUsed != "" {
+			result.Reverted = append(result.Reverted, i)
+		}
+	}
+	return result
+}
+
+// backtestRelay simulates the bundle as part of block with eth_callBundle.
+func (f *flashbot) backtestRelay(ctx context.Context, bundle *Bundle, block uint64) BacktestBlock {
+	result := BacktestBlock{Block: block, Profit: new(big.Int), MevGasPrice: new(big.Int)}
+	resp, err := f.callBundle(ctx, bundle, block, "0x"+strconv.FormatUint(block-1, 16))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if result.Profit, err = parseBigInt(resp.CoinbaseDiff); err != nil {
+		result.Err = fmt.Errorf("invalid coinbase diff: %w", err)
+		return result
+	}
+	if result.MevGasPrice, err = parseBigInt(resp.BundleGasPrice); err != nil {
+		result.Err = fmt.Errorf("invalid bundle gas price: %w", err)
+		return result
+	}
+	result.GasUsed = resp.TotalGasUsed
+	result.Success = true
+	for i, txResult := range resp.Results {
+		if txResult.Error == "" {
+			continue
+		}
+		result.Reverted = append(result.Reverted, i)
+		if result.Success && !bundle.canRevert(i) {
+			result.Success = false
+			result.Error = fmt.Sprintf("transaction %d (%s): %s", i, txResult.TxHash, txResult.Error)
+		}
+	}
+	return result
+}
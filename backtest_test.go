@@ -0,0 +1,125 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacktestRelay(t *testing.T) {
+	ctx := context.Background()
+	relay := newTestRelay(t)
+	relay.handle(methodEthCallBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		var p EthCallBundleParams
+		if err := json.Unmarshal(params[0], &p); err != nil {
+			return nil, invalidParams(t, err)
+		}
+		switch p.StateBlockNumber {
+		case "0x63":
+			assert.Equal(t, "0x64", p.BlockNumber)
+			return callBundleResp{CoinbaseDiff: "21000", BundleGasPrice: "1", TotalGasUsed: 21000,
+				Results: []callBundleTxResult{{GasUsed: 21000}}}, nil
+		case "0x64":
+			return callBundleResp{CoinbaseDiff: "0", BundleGasPrice: "0", TotalGasUsed: 30000,
+				Results: []callBundleTxResult{{GasUsed: 30000, Error: "execution reverted"}}}, nil
+		default:
+			return nil, &rpcError{Code: -32000, Message: "state not available"}
+		}
+	})
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+	tx, _ := newTestTx(t, 0)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx}}
+
+	report, err := fb.Backtest(ctx, bundle, 100, 102)
+	require.NoError(t, err)
+	require.Equal(t, BacktestModeRelay, report.Mode)
+	require.Len(t, report.Blocks, 3)
+
+	require.True(t, report.Blocks[0].Success)
+	require.Equal(t, uint64(21000), report.Blocks[0].GasUsed)
+	require.Zero(t, report.Blocks[0].Profit.Int64()-21000)
+
+	require.False(t, report.Blocks[1].Success)
+	require.Equal(t, []int{0}, report.Blocks[1].Reverted)
+	require.Contains(t, report.Blocks[1].Error, "execution reverted")
+
+	require.ErrorContains(t, report.Blocks[2].Err, "state not available")
+
+	require.Equal(t, 1, report.Succeeded)
+	require.Equal(t, 1, report.Profitable)
+	require.Equal(t, uint64(100), report.Best.Block)
+
+	// Allowing the revert makes the second block succeed.
+	bundle.CanRevert = []bool{true}
+	report, err = fb.Backtest(ctx, bundle, 100, 101, WithBacktestStep(1))
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Succeeded)
+	require.Equal(t, 1, report.Profitable)
+
+	_, err = fb.Backtest(ctx, bundle, 102, 100)
+	require.Error(t, err)
+	_, err = fb.Backtest(ctx, bundle, 100, 102, WithBacktestStep(0))
+	require.Error(t, err)
+	_, err = fb.Backtest(ctx, bundle, 100, 102, WithBacktestMode("archive"))
+	require.Error(t, err)
+}
+
+func TestBacktestLocal(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	node.setHead(100)
+	// Block 100 was built by another builder, the bundle pays it directly.
+	builder := common.HexToAddress("0x00000000000000000000000000000000000b0b0b")
+	node.coinbases[100] = builder
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	node.balances[crypto.PubkeyToAddress(key.PublicKey)] = big.NewInt(1e18)
+	// The nonce is past the sender's, so the bundle only runs with nonce checks skipped.
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(SepoliaChainID)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(SepoliaChainID),
+		Nonce:     5,
+		To:        &builder,
+		Value:     big.NewInt(1000),
+		Gas:       21000,
+		GasFeeCap: big.NewInt(2e9),
+		GasTipCap: big.NewInt(1e9),
+	})
+	require.NoError(t, err)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx}}
+
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC))
+	require.NoError(t, err)
+	report, err := fb.Backtest(ctx, bundle, 99, 100)
+	require.NoError(t, err)
+	require.Equal(t, BacktestModeLocal, report.Mode)
+	require.Len(t, report.Blocks, 2)
+	for _, b := range report.Blocks {
+		require.NoError(t, b.Err)
+		require.True(t, b.Success, b.Error)
+		require.Equal(t, uint64(21000), b.GasUsed)
+	}
+	// Block 99 only pays its builder the tip, block 100 the tip and the transfer.
+	require.Equal(t, big.NewInt(21000*1e9), report.Blocks[0].Profit)
+	require.Equal(t, big.NewInt(21000*1e9+1000), report.Blocks[1].Profit)
+	require.Equal(t, uint64(100), report.Best.Block)
+
+	// With nonce checks, the bundle is invalid in every block.
+	sim, err := NewLocalSimulator(ethC)
+	require.NoError(t, err)
+	report, err = fb.Backtest(ctx, bundle, 99, 100, WithBacktestSimulator(sim))
+	require.NoError(t, err)
+	require.Zero(t, report.Succeeded)
+	require.Contains(t, report.Blocks[0].Error, "nonce too high")
+
+	_, err = fb.Backtest(ctx, bundle, 100, 101)
+	require.ErrorContains(t, err, "past the latest block")
+}
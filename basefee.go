@@ -0,0 +1,155 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BaseFeePrediction is the projected base fee of a future block.
+type BaseFeePrediction struct {
+	// ParentBlock is the latest known block the projection starts from.
+	ParentBlock uint64
+	TargetBlock uint64
+	// Expected assumes blocks after the next one use exactly their gas target, so the base fee stops moving.
+	Expected *big.Int
+	// WorstCase assumes every block after the next one is full (+12.5% per block).
+	WorstCase *big.Int
+	// BestCase assumes every block after the next one is empty (-12.5% per block).
+	BestCase *big.Int
+}
+
+// FeeCapRecommendation is a GasFeeCap that keeps a transaction includable until MaxBlock.
+type FeeCapRecommendation struct {
+	TargetBlock uint64
+	MaxBlock    uint64
+	// BaseFee is the worst-case base fee at MaxBlock.
+	BaseFee   *big.Int
+	Tip       *big.Int
+	GasFeeCap *big.Int
+}
+
+// NextBaseFee applies the EIP-1559 update rule to a parent block's base fee, gas used and gas limit.
+func NextBaseFee(parentBaseFee *big.Int, gasUsed uint64, gasLimit uint64) *big.Int {
+	target := gasLimit / params.DefaultElasticityMultiplier
+	if target == 0 || gasUsed == target {
+		return new(big.Int).Set(parentBaseFee)
+	}
+
+	targetBig := new(big.Int).SetUint64(target)
+	denominator := big.NewInt(params.DefaultBaseFeeChangeDenominator)
+	if gasUsed > target {
+		delta := new(big.Int).SetUint64(gasUsed - target)
+		delta.Mul(delta, parentBaseFee)
+		delta.Div(delta, targetBig)
+		delta.Div(delta, denominator)
+		if delta.Sign() == 0 {
+			delta.SetInt64(1)
+		}
+		return delta.Add(delta, parentBaseFee)
+	}
+	delta := new(big.Int).SetUint64(target - gasUsed)
+	delta.Mul(delta, parentBaseFee)
+	delta.Div(delta, targetBig)
+	delta.Div(delta, denominator)
+	next := new(big.Int).Sub(parentBaseFee, delta)
+	if next.Sign() < 0 {
+		next.SetInt64(0)
+	}
+	return next
+}
+
+// ProjectBaseFee projects the base fee of targetBlock from the parent header.
+// The base fee of the block right after parent is exact; later blocks diverge into the expected, worst and best cases.
+func ProjectBaseFee(parent *types.Header, targetBlock uint64) (*BaseFeePrediction, error) {
+	if parent.BaseFee == nil {
+		return nil, fmt.Errorf("block %d has no base fee, EIP-1559 is not active", parent.Number.Uint64())
+	}
+	parentNumber := parent.Number.Uint64()
+	if targetBlock <= parentNumber {
+		return nil, fmt.Errorf("target block %d is not after block %d", targetBlock, parentNumber)
+	}
+
+	next := NextBaseFee(parent.BaseFee, parent.GasUsed, parent.GasLimit)
+	prediction := &BaseFeePrediction{
+		ParentBlock: parentNumber,
+		TargetBlock: targetBlock,
+		Expected:    next,
+		WorstCase:   new(big.Int).Set(next),
+		BestCase:    new(big.Int).Set(next),
+	}
+	for n := parentNumber + 2; n <= targetBlock; n++ {
+		prediction.WorstCase = NextBaseFee(prediction.WorstCase, parent.GasLimit, parent.GasLimit)
+		prediction.BestCase = NextBaseFee(prediction.BestCase, 0, parent.GasLimit)
+	}
+	return prediction, nil
+}
+
+// PredictBaseFee projects the base fee of targetBlock from the latest header.
+func (f *flashbot) PredictBaseFee(ctx context.Context, targetBlock uint64) (*BaseFeePrediction, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.PredictBaseFee")
+	defer span.End()
+
+	if f.ethC == nil {
+		span.SetStatus(codes.Error, ErrEthClientNotConfigured.Error())
+		return nil, ErrEthClientNotConfigured
+	}
+	header, err := f.ethC.HeaderByNumber(ctx, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	prediction, err := ProjectBaseFee(header, targetBlock)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetStatus(codes.Ok, "base fee predicted successfully")
+	return prediction, nil
+}
+
+// RecommendFeeCap returns a GasFeeCap covering the worst-case base fee at maxBlock plus tip,
+// so a bundle broadcast for targetBlock stays valid for its whole inclusion window.
+// maxBlock: 0 means the default Broadcast expiration after targetBlock.
+// tip: nil means the tip suggested by GetGasPrice.
+func (f *flashbot) RecommendFeeCap(ctx context.Context, targetBlock uint64, maxBlock uint64, tip *big.Int) (*FeeCapRecommendation, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.RecommendFeeCap")
+	defer span.End()
+
+	if maxBlock == 0 {
+		maxBlock = targetBlock + defaultBundleExpirationBlocks
+	}
+	if maxBlock < targetBlock {
+		span.SetStatus(codes.Error, "max block before target block")
+		return nil, fmt.Errorf("max block %d is before target block %d", maxBlock, targetBlock)
+	}
+	if tip == nil {
+		var err error
+		_, tip, err = f.GetGasPrice(ctx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+	prediction, err := f.PredictBaseFee(ctx, maxBlock)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetStatus(codes.Ok, "fee cap recommended successfully")
+	return &FeeCapRecommendation{
+		TargetBlock: targetBlock,
+		MaxBlock:    maxBlock,
+		BaseFee:     prediction.WorstCase,
+		Tip:         tip,
+		GasFeeCap:   new(big.Int).Add(prediction.WorstCase, tip),
+	}, nil
+}
@@ -0,0 +1,44 @@
+package flashbot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextBaseFee(t *testing.T) {
+	baseFee := big.NewInt(1e9)
+	require.Equal(t, big.NewInt(1e9), NextBaseFee(baseFee, 15_000_000, 30_000_000))
+	require.Equal(t, big.NewInt(1_125_000_000), NextBaseFee(baseFee, 30_000_000, 30_000_000))
+	require.Equal(t, big.NewInt(875_000_000), NextBaseFee(baseFee, 0, 30_000_000))
+	// Increases are at least 1 wei.
+	require.Equal(t, big.NewInt(8), NextBaseFee(big.NewInt(7), 15_000_001, 30_000_000))
+}
+
+func TestProjectBaseFee(t *testing.T) {
+	parent := &types.Header{
+		Number:   big.NewInt(100),
+		BaseFee:  big.NewInt(1e9),
+		GasUsed:  30_000_000,
+		GasLimit: 30_000_000,
+	}
+
+	next, err := ProjectBaseFee(parent, 101)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1_125_000_000), next.Expected)
+	require.Equal(t, next.Expected, next.WorstCase)
+	require.Equal(t, next.Expected, next.BestCase)
+
+	later, err := ProjectBaseFee(parent, 103)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1_125_000_000), later.Expected)
+	require.Equal(t, big.NewInt(1_423_828_125), later.WorstCase)
+	require.Equal(t, big.NewInt(861_328_125), later.BestCase)
+
+	_, err = ProjectBaseFee(parent, 100)
+	require.Error(t, err)
+	_, err = ProjectBaseFee(&types.Header{Number: big.NewInt(1)}, 2)
+	require.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package flashbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BlobBaseFeePrediction is the projected blob base fee of a future block.
+type BlobBaseFeePrediction struct {
+	TargetBlock uint64
+	// Expected assumes blob usage stays at target, so the blob base fee of the next block holds.
+	Expected *big.Int
+	// WorstCase assumes every block after the next one increases the blob base fee by the maximum 12.5%.
+	WorstCase *big.Int
+}
+
+// ValidateBlobTx checks that a blob transaction carries a sidecar whose blobs, commitments and KZG proofs
+// match its blob hashes. Builders drop blob transactions without a valid sidecar.
+func ValidateBlobTx(tx *types.Transaction) error {
+	if tx.Type() != types.BlobTxType {
+		return nil
+	}
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil {
+		return errors.New("blob transaction has no sidecar")
+	}
+	hashes := tx.BlobHashes()
+	if len(hashes) == 0 {
+		return errors.New("blob transaction has no blobs")
+	}
+	if len(sidecar.Blobs) != len(hashes) {
+		return fmt.Errorf("invalid number of %d blobs compared to %d blob hashes", len(sidecar.Blobs), len(hashes))
+	}
+	if err := sidecar.ValidateBlobCommitmentHashes(hashes); err != nil {
+		return err
+	}
+	switch sidecar.Version {
+	case types.BlobSidecarVersion0:
+		if len(sidecar.Proofs) != len(hashes) {
+			return fmt.Errorf("invalid number of %d blob proofs, expected %d", len(sidecar.Proofs), len(hashes))
+		}
+		for i := range sidecar.Blobs {
+			if err := kzg4844.VerifyBlobProof(&sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
+				return fmt.Errorf("invalid proof for blob %d: %w", i, err)
+			}
+		}
+	case types.BlobSidecarVersion1:
+		if len(sidecar.Proofs) != len(hashes)*kzg4844.CellProofsPerBlob {
+			return fmt.Errorf("invalid number of %d cell proofs, expected %d", len(sidecar.Proofs), len(hashes)*kzg4844.CellProofsPerBlob)
+		}
+		if err := kzg4844.VerifyCellProofs(sidecar.Blobs, sidecar.Commitments, sidecar.Proofs); err != nil {
+			return fmt.Errorf("invalid cell proofs: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported blob sidecar version %d", sidecar.Version)
+	}
+	return nil
+}
+
+// hasBlobTx reports whether the bundle contains a blob transaction.
+func (b *Bundle) hasBlobTx() bool {
+	for _, tx := range b.Transactions {
+		if tx.Type() == types.BlobTxType {
+			return true
+		}
+	}
+	return false
+}
+
+// PredictBlobBaseFee projects the blob base fee of targetBlock from the node's eth_blobBaseFee,
+// which is the blob base fee of the next block.
+func (f *flashbot) PredictBlobBaseFee(ctx context.Context, targetBlock uint64) (*BlobBaseFeePrediction, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.PredictBlobBaseFee")
+	defer span.End()
+
+	if f.ethC == nil {
+		span.SetStatus(codes.Error, ErrEthClientNotConfigured.Error())
+		return nil, ErrEthClientNotConfigured
+	}
+	head, err := f.ethC.BlockNumber(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+	if targetBlock <= head {
+		span.SetStatus(codes.Error, "target block already mined")
+		return nil, fmt.Errorf("target block %d is not after block %d", targetBlock, head)
+	}
+	next, err := f.ethC.BlobBaseFee(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get blob base fee: %w", err)
+	}
+
+	prediction := &BlobBaseFeePrediction{
+		TargetBlock: targetBlock,
+		Expected:    next,
+		WorstCase:   new(big.Int).Set(next),
+	}
+	denominator := big.NewInt(params.DefaultBaseFeeChangeDenominator)
+	for n := head + 2; n <= targetBlock; n++ {
+		increase := new(big.Int).Div(prediction.WorstCase, denominator)
+		if increase.Sign() == 0 {
+			increase.SetInt64(1)
+		}
+		prediction.WorstCase.Add(prediction.WorstCase, increase)
+	}
+	span.SetStatus(codes.Ok, "blob base fee predicted successfully")
+	return prediction, nil
+}
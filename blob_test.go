@@ -0,0 +1,58 @@
+package flashbot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBlobTx(t *testing.T) {
+	var blob kzg4844.Blob
+	blob[31] = 1
+	commitment, err := kzg4844.BlobToCommitment(&blob)
+	require.NoError(t, err)
+	proof, err := kzg4844.ComputeBlobProof(&blob, commitment)
+	require.NoError(t, err)
+	sidecar := types.NewBlobTxSidecar(types.BlobSidecarVersion0, []kzg4844.Blob{blob}, []kzg4844.Commitment{commitment}, []kzg4844.Proof{proof})
+
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	newBlobTx := func(sidecar *types.BlobTxSidecar, hashes []common.Hash) *types.Transaction {
+		tx, err := types.SignNewTx(pk, types.LatestSignerForChainID(big.NewInt(SepoliaChainID)), &types.BlobTx{
+			ChainID:    uint256.NewInt(SepoliaChainID),
+			Gas:        21000,
+			GasFeeCap:  uint256.NewInt(2e9),
+			GasTipCap:  uint256.NewInt(1e9),
+			BlobFeeCap: uint256.NewInt(1e9),
+			BlobHashes: hashes,
+			Sidecar:    sidecar,
+		})
+		require.NoError(t, err)
+		return tx
+	}
+
+	valid := newBlobTx(sidecar, sidecar.BlobHashes())
+	require.NoError(t, ValidateBlobTx(valid))
+	bundle := &Bundle{Transactions: []*types.Transaction{valid}}
+	require.True(t, bundle.hasBlobTx())
+	txs, err := bundle.encodedTransactions()
+	require.NoError(t, err)
+	// Network form carries the blob, so it is much larger than the bare transaction.
+	require.Greater(t, len(txs[0]), 2*len(blob))
+
+	require.Error(t, ValidateBlobTx(newBlobTx(nil, sidecar.BlobHashes())))
+	require.Error(t, ValidateBlobTx(newBlobTx(sidecar, []common.Hash{{0x01}})))
+
+	badProof := sidecar.Copy()
+	badProof.Proofs[0][10] ^= 0xff
+	require.Error(t, ValidateBlobTx(newBlobTx(badProof, sidecar.BlobHashes())))
+
+	legacy, _ := newTestTx(t, 0)
+	require.NoError(t, ValidateBlobTx(legacy))
+}
@@ -0,0 +1,233 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BuilderRegistry maps builder names to the fee recipient addresses they put in the coinbase of their blocks.
+// Names are matched case-insensitively.
+type BuilderRegistry map[string]common.Address
+
+// DefaultBuilderRegistry returns the fee recipients of well-known mainnet builders.
+func DefaultBuilderRegistry() BuilderRegistry {
+	return BuilderRegistry{
+		"flashbots":   common.HexToAddress("0xDAFEA492D9c6733ae3d56b7Ed1ADB60692c98Bc5"),
+		"beaverbuild": common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5"),
+		"titan":       common.HexToAddress("0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97"),
+		"rsync":       common.HexToAddress("0x1f9090aaE28b8a3dCeaDf281B0F12828e676c326"),
+	}
+}
+
+// Lookup returns the fee recipient of a builder.
+func (r BuilderRegistry) Lookup(builder string) (common.Address, bool) {
+	for name, addr := range r {
+		if strings.EqualFold(name, builder) {
+			return addr, true
+		}
+	}
+	return common.Address{}, false
+}
+
+// BuilderPayment describes a direct payment to the block builder appended to a bundle.
+// Exactly one of Amount or ProfitShareBps must be set, and one of Recipient or Builder.
+type BuilderPayment struct {
+	// Recipient is the address to pay. It takes precedence over Builder.
+	Recipient *common.Address
+	// Builder is looked up in the client's builder registry (WithBuilderRegistry).
+	Builder string
+	// Amount is a fixed payment in wei.
+	Amount *big.Int
+	// ProfitShareBps is the share of Simulation's profit to pay, in basis points (0, 10000]: 9000 pays 90%.
+	ProfitShareBps uint64
+	// Simulation is the simulation result ProfitShareBps is applied to.
+	Simulation *SimulateResponse
+	// Signer signs the payment transaction.
+	Signer TxSigner
+}
+
+// BuilderPaymentResult is a bundle with a builder payment appended.
+type BuilderPaymentResult struct {
+	// Bundle is a copy of the input bundle with the payment transaction appended.
+	Bundle    *Bundle
+	Recipient common.Address
+	Amount    *big.Int
+	// EffectiveGasPrice is what the builder earns per unit of gas for the whole bundle,
+	// priority fees plus the payment, at the latest base fee.
+	EffectiveGasPrice *big.Int
+}
+
+// AddBuilderPayment appends a transaction paying the builder directly, so the bundle competes on
+// more than its priority fees. The payment transaction cannot revert.
+func (f *flashbot) AddBuilderPayment(ctx context.Context, bundle *Bundle, payment BuilderPayment) (_ *BuilderPaymentResult, err error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.AddBuilderPayment")
+	defer span.End()
+
+	if f.ethC == nil {
+		span.SetStatus(codes.Error, ErrEthClientNotConfigured.Error())
+		return nil, ErrEthClientNotConfigured
+	}
+	if payment.Signer == nil {
+		span.SetStatus(codes.Error, "payment signer is nil")
+		return nil, fmt.Errorf("payment signer is required")
+	}
+
+	var recipient common.Address
+	switch {
+	case payment.Recipient != nil:
+		recipient = *payment.Recipient
+	case payment.Builder != "":
+		addr, ok := f.builderRegistry.Lookup(payment.Builder)
+		if !ok {
+			span.SetStatus(codes.Error, "unknown builder")
+			return nil, fmt.Errorf("unknown builder %q", payment.Builder)
+		}
+		recipient = addr
+	default:
+		span.SetStatus(codes.Error, "payment recipient is missing")
+		return nil, fmt.Errorf("either recipient or builder must be set")
+	}
+
+	amount, err := payment.amount()
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	chainID, err := f.getChainID(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// The payment must follow any transaction or authorization the payer already has in the bundle.
+	payer := payment.Signer.Address()
+	nonce, inBundle, err := bundle.nextNonce(chainID, payer)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	if !inBundle {
+		nonce, err = f.nonces.Reserve(ctx, payer, 1)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to reserve payer nonce: %w", err)
+		}
+		// Only a nonce reserved here is given back when the payment cannot be built.
+		defer func() {
+			if err != nil {
+				f.nonces.Release(payer, nonce)
+			}
+		}()
+	}
+
+	gasPrice, tip, err := f.GetGasPrice(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	paymentTx, err := payment.Signer.SignTx(ctx, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		To:        &recipient,
+		Value:     amount,
+		Gas:       params.TxGas,
+		GasFeeCap: gasPrice,
+		GasTipCap: tip,
+	}), chainID)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to sign payment transaction: %w", err)
+	}
+
+	paid := &Bundle{
+		Transactions:    append(append([]*types.Transaction{}, bundle.Transactions...), paymentTx),
+		CanRevert:       make([]bool, len(bundle.Transactions)+1),
+		ReplacementUUID: bundle.ReplacementUUID,
+		Builders:        bundle.Builders,
+		MinTimestamp:    bundle.MinTimestamp,
+	}
+	copy(paid.CanRevert, bundle.CanRevert)
+
+	header, err := f.ethC.HeaderByNumber(ctx, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "builder payment added successfully")
+	return &BuilderPaymentResult{
+		Bundle:            paid,
+		Recipient:         recipient,
+		Amount:            amount,
+		EffectiveGasPrice: BundleEffectiveGasPrice(paid, header.BaseFee, amount),
+	}, nil
+}
+
+// amount resolves the wei value of the payment.
+func (p *BuilderPayment) amount() (*big.Int, error) {
+	switch {
+	case p.Amount != nil && p.ProfitShareBps != 0:
+		return nil, fmt.Errorf("amount and profit share are mutually exclusive")
+	case p.Amount != nil:
+		if p.Amount.Sign() < 0 {
+			return nil, fmt.Errorf("payment amount cannot be negative")
+		}
+		return new(big.Int).Set(p.Amount), nil
+	case p.ProfitShareBps > 0 && p.ProfitShareBps <= maxBasisPoints:
+		if p.Simulation == nil {
+			return nil, fmt.Errorf("profit share requires a simulation result")
+		}
+		profit, err := parseBigInt(p.Simulation.Profit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse simulated profit: %w", err)
+		}
+		if profit.Sign() < 0 {
+			return nil, fmt.Errorf("simulated profit is negative")
+		}
+		// Rounded down, so the payment never exceeds the share.
+		amount := new(big.Int).Mul(profit, new(big.Int).SetUint64(p.ProfitShareBps))
+		return amount.Quo(amount, big.NewInt(maxBasisPoints)), nil
+	default:
+		return nil, fmt.Errorf("either amount or a profit share in (0, %d] basis points must be set", maxBasisPoints)
+	}
+}
+
+// BundleEffectiveGasPrice returns what a builder earns per unit of gas for including the bundle:
+// the priority fees of every transaction at baseFee plus coinbasePayment, divided by the bundle's gas.
+// Gas limits are used as gas usage, so the result is a lower bound for bundles that use less gas than signed.
+func BundleEffectiveGasPrice(bundle *Bundle, baseFee *big.Int, coinbasePayment *big.Int) *big.Int {
+	earned := new(big.Int)
+	if coinbasePayment != nil {
+		earned.Set(coinbasePayment)
+	}
+	totalGas := new(big.Int)
+	for _, tx := range bundle.Transactions {
+		gas := new(big.Int).SetUint64(tx.Gas())
+		totalGas.Add(totalGas, gas)
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			// The fee cap is below the base fee, the transaction pays nothing (and would not be included).
+			continue
+		}
+		earned.Add(earned, gas.Mul(gas, tip))
+	}
+	if totalGas.Sign() == 0 {
+		return new(big.Int)
+	}
+	return earned.Div(earned, totalGas)
+}
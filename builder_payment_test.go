@@ -0,0 +1,97 @@
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderRegistryLookup(t *testing.T) {
+	addr, ok := DefaultBuilderRegistry().Lookup("Titan")
+	require.True(t, ok)
+	require.Equal(t, common.HexToAddress("0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97"), addr)
+	_, ok = DefaultBuilderRegistry().Lookup("nobody")
+	require.False(t, ok)
+}
+
+func TestAddBuilderPayment(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	node.setHead(100)
+	fb, err := New(ctx, WithEthClient(ethC))
+	require.NoError(t, err)
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	payer := NewPrivateKeySigner(pk)
+	tx, _ := newTestTx(t, 0)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx}, CanRevert: []bool{true}}
+
+	t.Run("fixed amount", func(t *testing.T) {
+		res, err := fb.AddBuilderPayment(ctx, bundle, BuilderPayment{
+			Builder: "titan",
+			Amount:  big.NewInt(42_000e9),
+			Signer:  payer,
+		})
+		require.NoError(t, err)
+		titan, _ := DefaultBuilderRegistry().Lookup("titan")
+		require.Equal(t, titan, res.Recipient)
+		require.Len(t, res.Bundle.Transactions, 2)
+		require.Equal(t, []bool{true, false}, res.Bundle.CanRevert)
+		require.Len(t, bundle.Transactions, 1, "the input bundle is not modified")
+
+		paymentTx := res.Bundle.Transactions[1]
+		require.Equal(t, titan, *paymentTx.To())
+		require.Zero(t, paymentTx.Value().Cmp(big.NewInt(42_000e9)))
+		// Both transactions tip 1 gwei over the 1 gwei base fee, and the payment adds 1 gwei per gas of the bundle.
+		require.Zero(t, res.EffectiveGasPrice.Cmp(big.NewInt(2e9)))
+	})
+
+	t.Run("profit share", func(t *testing.T) {
+		recipient := common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+		profit, _ := new(big.Int).SetString("1000000000000000000000000000001", 10)
+		res, err := fb.AddBuilderPayment(ctx, bundle, BuilderPayment{
+			Recipient:      &recipient,
+			Builder:        "titan",
+			ProfitShareBps: 3333,
+			Simulation:     &SimulateResponse{Profit: profit.String()},
+			Signer:         payer,
+		})
+		require.NoError(t, err)
+		require.Equal(t, recipient, res.Recipient, "the recipient takes precedence over the builder")
+		require.Equal(t, "333300000000000000000000000000", res.Amount.String())
+	})
+
+	sim := &SimulateResponse{Profit: "1000"}
+	for name, payment := range map[string]BuilderPayment{
+		"amount and share":         {Builder: "titan", Amount: big.NewInt(1), ProfitShareBps: 100, Simulation: sim, Signer: payer},
+		"neither amount nor share": {Builder: "titan", Signer: payer},
+		"share above 100%":         {Builder: "titan", ProfitShareBps: 10_001, Simulation: sim, Signer: payer},
+		"share without simulation": {Builder: "titan", ProfitShareBps: 100, Signer: payer},
+		"negative amount":          {Builder: "titan", Amount: big.NewInt(-1), Signer: payer},
+		"no recipient":             {Amount: big.NewInt(1), Signer: payer},
+		"unknown builder":          {Builder: "nobody", Amount: big.NewInt(1), Signer: payer},
+		"no signer":                {Builder: "titan", Amount: big.NewInt(1)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := fb.AddBuilderPayment(ctx, bundle, payment)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestBundleEffectiveGasPrice(t *testing.T) {
+	tx, _ := newTestTx(t, 0) // 21000 gas, fee cap 2 gwei, tip 1 gwei
+	bundle := &Bundle{Transactions: []*types.Transaction{tx, tx}}
+
+	require.Zero(t, BundleEffectiveGasPrice(bundle, big.NewInt(1e9), nil).Cmp(big.NewInt(1e9)))
+	// The tip is capped by what the fee cap leaves over the base fee.
+	require.Zero(t, BundleEffectiveGasPrice(bundle, big.NewInt(1.5e9), nil).Cmp(big.NewInt(0.5e9)))
+	// A fee cap below the base fee pays nothing, only the coinbase payment counts.
+	require.Zero(t, BundleEffectiveGasPrice(bundle, big.NewInt(3e9), big.NewInt(84_000)).Cmp(big.NewInt(2)))
+	require.Zero(t, BundleEffectiveGasPrice(&Bundle{}, big.NewInt(1e9), big.NewInt(1)).Sign())
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -23,6 +24,43 @@ type Bundle struct {
 	// MaxTimestamp is the maximum timestamp for which the bundle is valid.
 }
 
+// encodedTransactions returns the hex-encoded signed transactions of the bundle in network form,
+// which carries the sidecar of blob transactions. Blob sidecars are validated first.
+func (b *Bundle) encodedTransactions() ([]string, error) {
+	txs := make([]string, 0, len(b.Transactions))
+	for i, tx := range b.Transactions {
+		if err := ValidateBlobTx(tx); err != nil {
+			return nil, fmt.Errorf("invalid blob transaction %d: %w", i, err)
+		}
+		bs, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transaction %d: %w", i, err)
+		}
+		txs = append(txs, hexutil.Encode(bs))
+	}
+	return txs, nil
+}
+
+// bodyItems returns the mev_sendBundle/mev_simBundle body of the bundle.
+func (b *Bundle) bodyItems() ([]mevSendBundleBodyItem, error) {
+	txs, err := b.encodedTransactions()
+	if err != nil {
+		return nil, err
+	}
+	body := make([]mevSendBundleBodyItem, 0, len(txs))
+	for i := range txs {
+		canRevert := false
+		if i < len(b.CanRevert) {
+			canRevert = b.CanRevert[i]
+		}
+		body = append(body, mevSendBundleBodyItem{
+			Tx:        &txs[i],
+			CanRevert: &canRevert,
+		})
+	}
+	return body, nil
+}
+
 // BundleOption is a function that can be used to configure the bundle.
 type BundleOption func(*mevSimBundleParams) error
 
@@ -35,6 +73,11 @@ type mevSimBundleParams struct {
 	Validity  *MevSendBundleValidity  `json:"validity,omitempty"`
 	Privacy   *MevSendBundlePrivacy   `json:"privacy,omitempty"`
 	Metadata  *MevSendBundleMetadata  `json:"metadata,omitempty"`
+
+	// skipPreflight makes Broadcast send the bundle without simulating it first.
+	skipPreflight bool
+	// simOptions is sent as the second parameter of mev_simBundle.
+	simOptions *MevSimBundleOptions
 }
 
 // mevSendBundleInclusion represents the inclusion block parameters for mev_sendBundle.
@@ -95,6 +138,15 @@ func WithMetadata(metadata MevSendBundleMetadata) BundleOption {
 	}
 }
 
+// WithSkipPreflight makes Broadcast send the bundle without simulating it or running the pre-flight policies,
+// for latency-critical paths where the bundle was already checked.
+func WithSkipPreflight() BundleOption {
+	return func(params *mevSimBundleParams) error {
+		params.skipPreflight = true
+		return nil
+	}
+}
+
 func WithExpirationDurationInBlocks(duration uint64) BundleOption {
 	return func(params *mevSimBundleParams) error {
 		block, err := strconv.ParseUint(strings.TrimPrefix(params.Inclusion.Block, "0x"), 16, 64)
@@ -0,0 +1,245 @@
+package flashbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ConfirmationEventType is the kind of event emitted by a ConfirmationTracker.
+type ConfirmationEventType string
+
+const (
+	// ConfirmationEventConfirmed is emitted once a landed bundle is buried under the tracker's depth.
+	ConfirmationEventConfirmed ConfirmationEventType = "confirmed"
+	// ConfirmationEventReorged is emitted when a block holding transactions of a landed bundle is no longer
+	// canonical.
+	ConfirmationEventReorged ConfirmationEventType = "reorged"
+)
+
+// ConfirmationEvent reports a change in the confirmation state of a tracked bundle.
+// Bundles stop being tracked after either event is emitted.
+type ConfirmationEvent struct {
+	Type   ConfirmationEventType
+	Bundle *Bundle
+	// Inclusion is the landing that was tracked.
+	Inclusion *InclusionResult
+	// Confirmations is the number of blocks (including the landing block) on top of the last height the
+	// bundle's transactions landed at.
+	Confirmations uint64
+	// CanonicalHash is the block hash at the landing height when the event was emitted. For reorg events it
+	// is the hash at the first landing height that is no longer canonical, the zero hash if the block is gone.
+	CanonicalHash common.Hash
+}
+
+// ConfirmationTracker follows landed bundles until they reach a confirmation depth,
+// and reports reorgs that drop them from the canonical chain so callers can resubmit.
+type ConfirmationTracker struct {
+	f      *flashbot
+	depth  uint64
+	events chan ConfirmationEvent
+
+	mu      sync.Mutex
+	tracked map[*Bundle]*InclusionResult
+	runOnce sync.Once
+}
+
+// ErrTrackerStarted is returned by ConfirmationTracker.Run when the tracker already ran.
+var ErrTrackerStarted = errors.New("confirmation tracker already started")
+
+// NewConfirmationTracker creates a tracker that considers a bundle final once it has depth confirmations.
+// depth: 0 means the default confirmation depth.
+func (f *flashbot) NewConfirmationTracker(depth uint64) (*ConfirmationTracker, error) {
+	if f.ethC == nil {
+		return nil, ErrEthClientNotConfigured
+	}
+	if depth == 0 {
+		depth = defaultConfirmationDepth
+	}
+	return &ConfirmationTracker{
+		f:       f,
+		depth:   depth,
+		events:  make(chan ConfirmationEvent, confirmationEventBuffer),
+		tracked: make(map[*Bundle]*InclusionResult),
+	}, nil
+}
+
+// Track starts following a landed bundle, usually with the result of WaitForInclusion.
+func (t *ConfirmationTracker) Track(bundle *Bundle, inclusion *InclusionResult) error {
+	if inclusion == nil || inclusion.BlockNumber == 0 {
+		return fmt.Errorf("bundle has not landed")
+	}
+	if inclusion.Status != InclusionStatusIncluded && inclusion.Status != InclusionStatusPartiallyIncluded {
+		return fmt.Errorf("cannot track bundle with status %q", inclusion.Status)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tracked[bundle] = inclusion
+	return nil
+}
+
+// Untrack stops following a bundle without emitting an event.
+func (t *ConfirmationTracker) Untrack(bundle *Bundle) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.tracked, bundle)
+}
+
+// Pending returns the number of bundles still being tracked.
+func (t *ConfirmationTracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.tracked)
+}
+
+// Events returns the channel on which confirmation and reorg events are delivered.
+// The channel is closed when Run returns.
+func (t *ConfirmationTracker) Events() <-chan ConfirmationEvent {
+	return t.events
+}
+
+// Run polls the chain at the client's poll interval until ctx is done.
+// A tracker runs once: Run returns ErrTrackerStarted when called again.
+func (t *ConfirmationTracker) Run(ctx context.Context) error {
+	started := true
+	t.runOnce.Do(func() { started = false })
+	if started {
+		return ErrTrackerStarted
+	}
+	defer close(t.events)
+	ticker := time.NewTicker(t.f.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := t.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			t.f.logger.WithError(err).Warn("failed to poll confirmations")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll checks every tracked bundle against the current canonical chain.
+func (t *ConfirmationTracker) poll(ctx context.Context) error {
+	ctx, span := t.f.tracer.Start(ctx, "flashbot.ConfirmationTracker.poll")
+	defer span.End()
+
+	t.mu.Lock()
+	tracked := make(map[*Bundle]*InclusionResult, len(t.tracked))
+	for b, inc := range t.tracked {
+		tracked[b] = inc
+	}
+	t.mu.Unlock()
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	head, err := t.f.ethC.BlockNumber(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	// Several bundles often land in the same block, so each height is only fetched once per poll.
+	hashes := make(map[uint64]common.Hash)
+	var pollErr error
+bundles:
+	for bundle, inc := range tracked {
+		blocks := landedBlocks(inc)
+		last := blocks[len(blocks)-1]
+		if head < last.number {
+			// The node has not caught up with the landing yet, as when a load balancer switched nodes.
+			continue
+		}
+		event := ConfirmationEvent{
+			Bundle:        bundle,
+			Inclusion:     inc,
+			Confirmations: head - last.number + 1,
+		}
+		for _, block := range blocks {
+			hash, ok := hashes[block.number]
+			if !ok {
+				header, err := t.f.ethC.HeaderByNumber(ctx, new(big.Int).SetUint64(block.number))
+				switch {
+				case errors.Is(err, ethereum.NotFound):
+					// The head is past the landing height but the block is gone, the zero hash reports the reorg.
+				case err != nil:
+					// The other bundles are still checked, this one is retried on the next poll.
+					span.RecordError(err)
+					if pollErr == nil {
+						pollErr = fmt.Errorf("failed to get header %d: %w", block.number, err)
+					}
+					continue bundles
+				default:
+					hash = header.Hash()
+				}
+				hashes[block.number] = hash
+			}
+			if hash != block.hash {
+				event.Type = ConfirmationEventReorged
+				event.CanonicalHash = hash
+				break
+			}
+			if block.number == inc.BlockNumber {
+				event.CanonicalHash = hash
+			}
+		}
+		if event.Type == "" {
+			if event.Confirmations < t.depth {
+				continue
+			}
+			event.Type = ConfirmationEventConfirmed
+		}
+
+		select {
+		case t.events <- event:
+			t.Untrack(bundle)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if pollErr != nil {
+		span.SetStatus(codes.Error, pollErr.Error())
+		return pollErr
+	}
+	span.SetStatus(codes.Ok, "confirmations polled successfully")
+	return nil
+}
+
+// landedBlock is a block holding transactions of a tracked bundle.
+type landedBlock struct {
+	number uint64
+	hash   common.Hash
+}
+
+// landedBlocks returns the distinct blocks the bundle's transactions landed in, by height.
+func landedBlocks(inc *InclusionResult) []landedBlock {
+	blocks := []landedBlock{{number: inc.BlockNumber, hash: inc.BlockHash}}
+	seen := map[landedBlock]bool{blocks[0]: true}
+	for _, tx := range inc.Transactions {
+		block := landedBlock{number: tx.BlockNumber, hash: tx.BlockHash}
+		if !tx.Included || seen[block] {
+			continue
+		}
+		seen[block] = true
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].number < blocks[j].number
+	})
+	return blocks
+}
@@ -0,0 +1,121 @@
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmationTracker(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	node, ethC := newTestNode(t)
+	node.setHead(100)
+	fb, err := New(ctx, WithEthClient(ethC), WithPollInterval(5*time.Millisecond))
+	require.NoError(t, err)
+
+	landed := func(block uint64) (*Bundle, *InclusionResult) {
+		tx, _ := newTestTx(t, 0)
+		header, err := ethC.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+		require.NoError(t, err)
+		return &Bundle{Transactions: []*types.Transaction{tx}}, &InclusionResult{
+			Status:      InclusionStatusIncluded,
+			BlockNumber: block,
+			BlockHash:   header.Hash(),
+		}
+	}
+	confirmed, confirmedInc := landed(98)
+	reorged, reorgedInc := landed(99)
+	untracked, untrackedInc := landed(100)
+	// partial landed its first transaction in block 98 and its second in block 100.
+	partial, partialInc := landed(98)
+	_, secondInc := landed(100)
+	partialInc.Status = InclusionStatusPartiallyIncluded
+	partialInc.Transactions = []TxInclusion{
+		{Included: true, BlockNumber: 98, BlockHash: partialInc.BlockHash},
+		{Included: true, BlockNumber: 100, BlockHash: secondInc.BlockHash},
+	}
+	// The node serving the tracker lags behind the one that reported the landing in block 101.
+	node.setHead(101)
+	lagging, laggingInc := landed(101)
+	node.setHead(100)
+
+	tracker, err := fb.NewConfirmationTracker(3)
+	require.NoError(t, err)
+	require.Error(t, tracker.Track(confirmed, &InclusionResult{Status: InclusionStatusExpired, BlockNumber: 98}))
+	for bundle, inc := range map[*Bundle]*InclusionResult{
+		confirmed: confirmedInc, reorged: reorgedInc, untracked: untrackedInc, partial: partialInc, lagging: laggingInc,
+	} {
+		require.NoError(t, tracker.Track(bundle, inc))
+	}
+	tracker.Untrack(untracked)
+	require.Equal(t, 4, tracker.Pending())
+
+	done := make(chan error, 1)
+	go func() { done <- tracker.Run(ctx) }()
+
+	// Block 98 has 3 confirmations at head 100, partial waits for its second block to get them.
+	event := <-tracker.Events()
+	require.Equal(t, ConfirmationEventConfirmed, event.Type)
+	require.Same(t, confirmed, event.Bundle)
+	require.Equal(t, uint64(3), event.Confirmations)
+	require.Equal(t, confirmedInc.BlockHash, event.CanonicalHash)
+
+	// Siblings replace blocks 99 and 100.
+	node.reorg(99)
+	node.reorg(100)
+	events := map[*Bundle]ConfirmationEvent{}
+	for range 2 {
+		event := <-tracker.Events()
+		events[event.Bundle] = event
+	}
+	require.Equal(t, ConfirmationEventReorged, events[reorged].Type)
+	require.NotEqual(t, reorgedInc.BlockHash, events[reorged].CanonicalHash)
+	require.Equal(t, ConfirmationEventReorged, events[partial].Type)
+	require.NotEqual(t, secondInc.BlockHash, events[partial].CanonicalHash)
+	require.NotEqual(t, common.Hash{}, events[partial].CanonicalHash)
+
+	// The lagging node reaches block 101 and confirms it.
+	require.Equal(t, 1, tracker.Pending())
+	node.setHead(103)
+	event = <-tracker.Events()
+	require.Equal(t, ConfirmationEventConfirmed, event.Type)
+	require.Same(t, lagging, event.Bundle)
+	require.Zero(t, tracker.Pending())
+
+	require.ErrorIs(t, tracker.Run(ctx), ErrTrackerStarted)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	_, open := <-tracker.Events()
+	require.False(t, open)
+}
+
+func TestConfirmationTrackerCancelledSend(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	node.setHead(100)
+	fb, err := New(ctx, WithEthClient(ethC))
+	require.NoError(t, err)
+	header, err := ethC.HeaderByNumber(ctx, big.NewInt(98))
+	require.NoError(t, err)
+	tx, _ := newTestTx(t, 0)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx}}
+
+	tracker, err := fb.NewConfirmationTracker(1)
+	require.NoError(t, err)
+	// Fill the events channel so the confirmation cannot be delivered.
+	for range cap(tracker.events) {
+		tracker.events <- ConfirmationEvent{}
+	}
+	require.NoError(t, tracker.Track(bundle, &InclusionResult{Status: InclusionStatusIncluded, BlockNumber: 98, BlockHash: header.Hash()}))
+	pollCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, tracker.poll(pollCtx), context.DeadlineExceeded)
+	// The undelivered event is not lost, the bundle is still tracked.
+	require.Equal(t, 1, tracker.Pending())
+}
@@ -1,11 +1,16 @@
 package flashbot
 
+import "time"
+
 const (
 	MainnetChainID = 1
 	SepoliaChainID = 11155111
 
 	MainnetRelayURL = "https://relay.flashbots.net"
 	SepoliaRelayURL = "https://relay-sepolia.flashbots.net"
+
+	MainnetMevShareStreamURL = "https://mev-share.flashbots.net"
+	SepoliaMevShareStreamURL = "https://mev-share-sepolia.flashbots.net"
 )
 
 const (
@@ -62,3 +67,43 @@ const (
 const (
 	jsonRPCVersion = "2.0"
 )
+
+const (
+	// defaultPollInterval is how often the Ethereum node is polled while waiting on chain events.
+	defaultPollInterval = 2 * time.Second
+	// defaultBundleExpirationBlocks is how many blocks past the target block a broadcast bundle stays valid.
+	defaultBundleExpirationBlocks = 30
+	// defaultConfirmationDepth is the number of confirmations after which a landed bundle is considered final.
+	defaultConfirmationDepth = 12
+	// feeHistoryBlocks is the number of recent blocks gas strategies look at.
+	feeHistoryBlocks = 20
+	// defaultGasSafetyMargin is the percentage added on top of estimated gas to recommend gas limits.
+	defaultGasSafetyMargin = 25
+	// confirmationEventBuffer is the capacity of a ConfirmationTracker's event channel.
+	confirmationEventBuffer = 64
+	// defaultNonceStuckAfter is how long the lowest unconfirmed nonce may stay reserved before it is reported stuck.
+	defaultNonceStuckAfter = 2 * time.Minute
+	// defaultSimCacheHeadCheck is how often a SimulationCache reads the latest head, at most.
+	defaultSimCacheHeadCheck = time.Second
+	// simCacheCallTimeout bounds a simulation shared by the callers of a SimulationCache, which runs detached
+	// from the context of the caller that started it.
+	simCacheCallTimeout = 30 * time.Second
+	// defaultSimulateWorkers is how many simulations SimulateMany runs at the same time.
+	defaultSimulateWorkers = 8
+	// defaultSimulateDeadlineMargin is how long before the target block's timestamp SimulateMany stops.
+	defaultSimulateDeadlineMargin = time.Second
+	// defaultOptimizeBudget is how many orderings OptimizeBundle simulates at most, every ordering of 5 transactions.
+	defaultOptimizeBudget = 120
+	// defaultHintStreamMinBackoff is how long a MevShareStream waits before its first reconnect attempt.
+	defaultHintStreamMinBackoff = 500 * time.Millisecond
+	// defaultHintStreamMaxBackoff caps the doubling delay between a MevShareStream's reconnect attempts.
+	defaultHintStreamMaxBackoff = 30 * time.Second
+	// hintEventBuffer is the capacity of a MevShareStream's event channel.
+	hintEventBuffer = 256
+	// maxHintEventSize is the longest line a MevShareStream reads, large enough for hints with full calldata.
+	maxHintEventSize = 4 << 20
+	// maxBasisPoints is 100% in basis points.
+	maxBasisPoints = 10_000
+	// secondsPerSlot is the time between two post-merge blocks.
+	secondsPerSlot = 12
+)
@@ -0,0 +1,173 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// CostRange is a wei amount bounded by its best, expected and worst case.
+type CostRange struct {
+	Min      *big.Int
+	Expected *big.Int
+	Max      *big.Int
+}
+
+func newCostRange() CostRange {
+	return CostRange{Min: new(big.Int), Expected: new(big.Int), Max: new(big.Int)}
+}
+
+// add adds other to r in place.
+func (r CostRange) add(other CostRange) {
+	r.Min.Add(r.Min, other.Min)
+	r.Expected.Add(r.Expected, other.Expected)
+	r.Max.Add(r.Max, other.Max)
+}
+
+// TxCost is the wei a single bundle transaction costs its sender.
+type TxCost struct {
+	TxHash common.Hash
+	Sender common.Address
+	Type   uint8
+	// GasUsed is the gas the Min and Expected fees are computed with, GasLimit the one Max is computed with.
+	GasUsed  uint64
+	GasLimit uint64
+	// BlobGas is the blob gas of blob transactions.
+	BlobGas uint64
+	// Fee is the execution fee, plus the blob fee for blob transactions.
+	Fee   CostRange
+	Value *big.Int
+	// Total is Fee plus Value.
+	Total CostRange
+}
+
+// BundleCost is the wei a bundle costs at the projected base fee of its target block.
+type BundleCost struct {
+	TargetBlock uint64
+	BaseFee     *BaseFeePrediction
+	// BlobBaseFee is only set for bundles with blob transactions.
+	BlobBaseFee *BlobBaseFeePrediction
+	// Transactions holds the cost of each transaction, in bundle order.
+	Transactions []TxCost
+	// BySender holds the total cost (fees and value) per sender.
+	BySender map[common.Address]CostRange
+	// Total is the total cost of the bundle, fees and value.
+	Total CostRange
+}
+
+// EstimateBundleCost prices the bundle at the projected base fee of targetBlock.
+// Min uses the best-case base fee and Expected the expected one, both with the gas used from EstimateGasBundle
+// (the signed gas limits unless an estimate mode is given). Max is the most the signed transactions can cost:
+// gas limit times fee cap (or gas price for legacy and access list transactions).
+// Blob transactions add their blob gas priced at the projected blob base fee (Min and Expected use the
+// expected blob base fee) or at the blob fee cap (Max).
+func (f *flashbot) EstimateBundleCost(ctx context.Context, bundle *Bundle, targetBlock uint64, opts ...EstimateOption) (*BundleCost, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.EstimateBundleCost")
+	defer span.End()
+
+	chainID, err := f.getChainID(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	estimate, err := f.EstimateGasBundle(ctx, bundle, opts...)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	prediction, err := f.PredictBaseFee(ctx, targetBlock)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Blob fees are priced at the blob base fee, capped by each transaction's blob fee cap.
+	var blobPrediction *BlobBaseFeePrediction
+	var expectedBlobFee *big.Int
+	if bundle.hasBlobTx() {
+		blobPrediction, err = f.PredictBlobBaseFee(ctx, targetBlock)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
+		expectedBlobFee = blobPrediction.Expected
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
+	cost := &BundleCost{
+		TargetBlock:  targetBlock,
+		BaseFee:      prediction,
+		BlobBaseFee:  blobPrediction,
+		Transactions: make([]TxCost, len(bundle.Transactions)),
+		BySender:     make(map[common.Address]CostRange),
+		Total:        newCostRange(),
+	}
+	for i, tx := range bundle.Transactions {
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+		}
+		txCost := TxCost{
+			TxHash:   tx.Hash(),
+			Sender:   sender,
+			Type:     tx.Type(),
+			GasUsed:  estimate.GasUsed[i],
+			GasLimit: tx.Gas(),
+			BlobGas:  tx.BlobGas(),
+			Fee: CostRange{
+				Min:      txFee(tx, estimate.GasUsed[i], prediction.BestCase, expectedBlobFee),
+				Expected: txFee(tx, estimate.GasUsed[i], prediction.Expected, expectedBlobFee),
+				Max:      txFee(tx, tx.Gas(), nil, nil),
+			},
+			Value: new(big.Int).Set(tx.Value()),
+			Total: newCostRange(),
+		}
+		txCost.Total.add(txCost.Fee)
+		txCost.Total.add(CostRange{Min: tx.Value(), Expected: tx.Value(), Max: tx.Value()})
+		cost.Transactions[i] = txCost
+
+		if _, ok := cost.BySender[sender]; !ok {
+			cost.BySender[sender] = newCostRange()
+		}
+		cost.BySender[sender].add(txCost.Total)
+		cost.Total.add(txCost.Total)
+	}
+	span.SetStatus(codes.Ok, "bundle cost estimated successfully")
+	return cost, nil
+}
+
+// txFee returns what tx pays for gas units of execution at baseFee, plus its blob gas at blobBaseFee.
+// A nil baseFee or blobBaseFee prices at the respective fee cap, the most the transaction can pay.
+func txFee(tx *types.Transaction, gas uint64, baseFee *big.Int, blobBaseFee *big.Int) *big.Int {
+	var price *big.Int
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		price = tx.GasPrice()
+	default:
+		price = tx.GasFeeCap()
+		if baseFee != nil {
+			if withTip := new(big.Int).Add(baseFee, tx.GasTipCap()); withTip.Cmp(price) < 0 {
+				price = withTip
+			}
+		}
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gas), price)
+	if tx.Type() == types.BlobTxType {
+		blobPrice := tx.BlobGasFeeCap()
+		if blobBaseFee != nil && blobBaseFee.Cmp(blobPrice) < 0 {
+			blobPrice = blobBaseFee
+		}
+		fee.Add(fee, new(big.Int).Mul(new(big.Int).SetUint64(tx.BlobGas()), blobPrice))
+	}
+	return fee
+}
@@ -0,0 +1,88 @@
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateBundleCost(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	node.setHead(100)
+	fb, err := New(ctx, WithEthClient(ethC))
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x4bfD011E2bE77b57A42882f2e854a235a7D18646")
+	signer := types.LatestSignerForChainID(big.NewInt(SepoliaChainID))
+	keyA, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyB, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	legacy, err := types.SignNewTx(keyA, signer, &types.LegacyTx{
+		Nonce: 0, To: &to, Value: big.NewInt(5), Gas: 21000, GasPrice: big.NewInt(3e9),
+	})
+	require.NoError(t, err)
+	accessList, err := types.SignNewTx(keyA, signer, &types.AccessListTx{
+		ChainID: big.NewInt(SepoliaChainID), Nonce: 1, To: &to, Gas: 30000, GasPrice: big.NewInt(2e9),
+	})
+	require.NoError(t, err)
+	dynamic, err := types.SignNewTx(keyB, signer, &types.DynamicFeeTx{
+		ChainID: big.NewInt(SepoliaChainID), Nonce: 0, To: &to, Value: big.NewInt(1), Gas: 21000,
+		GasFeeCap: big.NewInt(2e9), GasTipCap: big.NewInt(0.5e9),
+	})
+	require.NoError(t, err)
+	blob, err := types.SignNewTx(keyB, signer, &types.BlobTx{
+		ChainID: uint256.NewInt(SepoliaChainID), Nonce: 1, To: to, Gas: 21000,
+		GasFeeCap: uint256.NewInt(3e9), GasTipCap: uint256.NewInt(1e9),
+		BlobFeeCap: uint256.NewInt(50), BlobHashes: []common.Hash{{0x01}},
+	})
+	require.NoError(t, err)
+	bundle := &Bundle{Transactions: []*types.Transaction{legacy, accessList, dynamic, blob}}
+
+	// Block 101 has the head's 1 gwei base fee (its parent used exactly its gas target), block 102 at best
+	// 0.875 gwei; the blob base fee is 10 wei.
+	cost, err := fb.EstimateBundleCost(ctx, bundle, 102)
+	require.NoError(t, err)
+	require.Zero(t, cost.BaseFee.Expected.Cmp(big.NewInt(1e9)))
+	require.Zero(t, cost.BaseFee.BestCase.Cmp(big.NewInt(875e6)))
+	require.Zero(t, cost.BlobBaseFee.Expected.Cmp(big.NewInt(testBlobBaseFee)))
+
+	wei := func(s string) *big.Int {
+		v, ok := new(big.Int).SetString(s, 10)
+		require.True(t, ok)
+		return v
+	}
+	requireRange := func(r CostRange, min, expected, max string) {
+		t.Helper()
+		require.Equal(t, wei(min).String(), r.Min.String(), "min")
+		require.Equal(t, wei(expected).String(), r.Expected.String(), "expected")
+		require.Equal(t, wei(max).String(), r.Max.String(), "max")
+	}
+
+	require.Len(t, cost.Transactions, 4)
+	// Legacy and access list transactions pay their gas price whatever the base fee.
+	requireRange(cost.Transactions[0].Fee, "63000000000000", "63000000000000", "63000000000000")
+	requireRange(cost.Transactions[0].Total, "63000000000005", "63000000000005", "63000000000005")
+	requireRange(cost.Transactions[1].Fee, "60000000000000", "60000000000000", "60000000000000")
+	// Dynamic fee: 21000 * min(base fee + 0.5 gwei, 2 gwei).
+	requireRange(cost.Transactions[2].Fee, "28875000000000", "31500000000000", "42000000000000")
+	requireRange(cost.Transactions[2].Total, "28875000000001", "31500000000001", "42000000000001")
+	// Blob: 21000 * min(base fee + 1 gwei, 3 gwei) plus one blob of gas at 10 wei, or at its 50 wei cap.
+	require.Equal(t, uint64(params.BlobTxBlobGasPerBlob), cost.Transactions[3].BlobGas)
+	requireRange(cost.Transactions[3].Fee, "39375001310720", "42000001310720", "63000006553600")
+
+	senderA := crypto.PubkeyToAddress(keyA.PublicKey)
+	senderB := crypto.PubkeyToAddress(keyB.PublicKey)
+	require.Len(t, cost.BySender, 2)
+	requireRange(cost.BySender[senderA], "123000000000005", "123000000000005", "123000000000005")
+	requireRange(cost.BySender[senderB], "68250001310721", "73500001310721", "105000006553601")
+	requireRange(cost.Total, "191250001310726", "196500001310726", "228000006553606")
+}
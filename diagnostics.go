@@ -0,0 +1,238 @@
+package flashbot
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FailureCategory classifies why a bundle failed in simulation or was rejected by the relay.
+type FailureCategory string
+
+const (
+	FailureNonceTooLow       FailureCategory = "nonce_too_low"
+	FailureNonceTooHigh      FailureCategory = "nonce_too_high"
+	FailureInsufficientFunds FailureCategory = "insufficient_funds"
+	FailureFeeCapTooLow      FailureCategory = "fee_cap_too_low"
+	FailureIntrinsicGas      FailureCategory = "intrinsic_gas_too_low"
+	FailureBundleTooLarge    FailureCategory = "bundle_too_large"
+	FailureUnknownBuilder    FailureCategory = "unknown_builder"
+	FailureReverted          FailureCategory = "reverted"
+	FailureUnknown           FailureCategory = "unknown"
+)
+
+// failureRules maps the messages of go-ethereum and the relays to categories, most specific first. They are
+// only tried on messages that are not reverts.
+var failureRules = []struct {
+	category   FailureCategory
+	patterns   []string
+	suggestion string
+}{
+	{
+		category:   FailureNonceTooLow,
+		patterns:   []string{"nonce too low"},
+		suggestion: "the sender's nonce has moved past the transaction's, usually because it or another transaction of the sender landed; re-sign it with the pending nonce (NonceManager.Resync)",
+	},
+	{
+		category:   FailureNonceTooHigh,
+		patterns:   []string{"nonce too high"},
+		suggestion: "a transaction of the sender with a lower nonce is missing; put it earlier in the bundle or re-sign with the pending nonce",
+	},
+	{
+		category:   FailureInsufficientFunds,
+		patterns:   []string{"insufficient funds for gas * price + value", "insufficient funds for transfer"},
+		suggestion: "the sender cannot pay gas limit * max fee per gas + value; fund it earlier in the bundle (FundingConstraints), or lower the gas limit, fees or value",
+	},
+	{
+		category:   FailureFeeCapTooLow,
+		patterns:   []string{"max fee per gas less than block base fee", "fee cap less than block base fee", "feecap too low"},
+		suggestion: "the max fee per gas is below the block's base fee; raise it to cover the base fee of the last target block (RecommendFeeCap)",
+	},
+	{
+		category:   FailureIntrinsicGas,
+		patterns:   []string{"intrinsic gas too low", "floor data gas"},
+		suggestion: "the gas limit does not cover the transaction's intrinsic cost; raise it (EstimateGasBundle)",
+	},
+	{
+		category:   FailureBundleTooLarge,
+		patterns:   []string{"bundle too large", "too many transactions", "too many txs", "gas limit reached", "exceeds block gas limit"},
+		suggestion: "the bundle exceeds the relay's transaction count or the block's gas limit; split it or drop transactions",
+	},
+	{
+		category:   FailureUnknownBuilder,
+		patterns:   []string{"unknown builder", "invalid builder", "builder not found"},
+		suggestion: "the relay does not know a requested builder; check the names given to WithBuilders",
+	},
+	{
+		category:   FailureReverted,
+		patterns:   []string{"execution reverted", "reverted"},
+		suggestion: "a transaction reverted; check its revert reason, or allow it to revert with CanRevert",
+	},
+}
+
+var (
+	// txPrefixPattern matches the "transaction 2 (0x...)" prefix of the local simulator's errors.
+	txPrefixPattern = regexp.MustCompile(`transaction (\d+) \(`)
+	txHashPattern   = regexp.MustCompile(`0x[0-9a-fA-F]{64}`)
+	addressPattern  = regexp.MustCompile(`address (0x[0-9a-fA-F]{40})`)
+	txNoncePattern  = regexp.MustCompile(`tx: (\d+)`)
+)
+
+// Diagnosis is a classified bundle failure.
+type Diagnosis struct {
+	Category FailureCategory
+	// TxIndex is the index of the offending transaction in the bundle, -1 when the failure concerns the
+	// whole bundle or no transaction could be singled out.
+	TxIndex int
+	TxHash  string
+	// Message is the relay's or simulator's message, Code the JSON-RPC error code when the relay rejected
+	// the request.
+	Message string
+	Code    int
+	// Suggestion tells how the failure is usually fixed; empty for FailureUnknown.
+	Suggestion string
+}
+
+func (d *Diagnosis) String() string {
+	s := string(d.Category)
+	if d.TxIndex >= 0 {
+		s += fmt.Sprintf(" in transaction %d (%s)", d.TxIndex, d.TxHash)
+	}
+	s += ": " + d.Message
+	if d.Suggestion != "" {
+		s += "; " + d.Suggestion
+	}
+	return s
+}
+
+// Diagnose classifies the failure of a Simulate or Broadcast call on the bundle from its error and, when
+// there is one, its simulation response. It returns nil when neither reports a failure.
+func Diagnose(bundle *Bundle, res *SimulateResponse, err error) *Diagnosis {
+	d := &Diagnosis{Category: FailureUnknown, TxIndex: -1}
+	var revertErr *SimulationRevertError
+	var invalidErr *SimulationInvalidError
+	var rpcErr *rpcError
+	switch {
+	case errors.As(err, &revertErr):
+		d.Message = revertErr.Error()
+		d.TxIndex, d.TxHash = revertErr.TxIndex, revertErr.TxHash
+	case errors.As(err, &invalidErr):
+		d.Message = invalidErr.Err
+		d.TxIndex, d.TxHash = invalidErr.TxIndex, invalidErr.TxHash
+	case errors.As(err, &rpcErr):
+		d.Message = rpcErr.Message
+		d.Code = rpcErr.Code
+	case err != nil:
+		d.Message = err.Error()
+	case res != nil && !res.Success:
+		d.Message = res.Error
+		if d.Message == "" {
+			d.Message = res.ExecError
+		}
+		for i, txResult := range res.Logs {
+			if txResult.Error != "" && (bundle == nil || !bundle.canRevert(i)) {
+				d.TxIndex, d.TxHash = i, txResult.TxHash
+				if d.Message == "" {
+					d.Message = txResult.Error
+				}
+				break
+			}
+		}
+	default:
+		return nil
+	}
+
+	lower := strings.ToLower(d.Message)
+	if revertErr != nil || strings.Contains(lower, "execution reverted") {
+		// The revert reason is the contract's, its words say nothing about the transaction's validity.
+		d.Category = FailureReverted
+		d.Suggestion = failureSuggestion(FailureReverted)
+	} else {
+		for _, rule := range failureRules {
+			if containsAny(lower, rule.patterns) {
+				d.Category = rule.category
+				d.Suggestion = rule.suggestion
+				break
+			}
+		}
+	}
+	if bundle == nil {
+		return d
+	}
+	if d.TxIndex < 0 && d.Category != FailureBundleTooLarge && d.Category != FailureUnknownBuilder {
+		d.TxIndex = offendingTx(bundle, d.Message)
+	}
+	if d.TxIndex >= 0 && d.TxIndex < len(bundle.Transactions) {
+		d.TxHash = bundle.Transactions[d.TxIndex].Hash().Hex()
+	} else {
+		d.TxIndex, d.TxHash = -1, ""
+	}
+	return d
+}
+
+// failureSuggestion returns the suggested fix of a category.
+func failureSuggestion(category FailureCategory) string {
+	for _, rule := range failureRules {
+		if rule.category == category {
+			return rule.suggestion
+		}
+	}
+	return ""
+}
+
+// offendingTx finds the transaction a failure message is about: by its index, by its hash, or by its
+// sender and nonce. It returns -1 when none matches.
+func offendingTx(bundle *Bundle, msg string) int {
+	if m := txPrefixPattern.FindStringSubmatch(msg); m != nil {
+		if i, err := strconv.Atoi(m[1]); err == nil {
+			return i
+		}
+	}
+	for _, h := range txHashPattern.FindAllString(msg, -1) {
+		hash := common.HexToHash(h)
+		for i, tx := range bundle.Transactions {
+			if tx.Hash() == hash {
+				return i
+			}
+		}
+	}
+	m := addressPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return -1
+	}
+	sender := common.HexToAddress(m[1])
+	nonce := int64(-1)
+	if n := txNoncePattern.FindStringSubmatch(msg); n != nil {
+		if v, err := strconv.ParseInt(n[1], 10, 64); err == nil {
+			nonce = v
+		}
+	}
+	first := -1
+	for i, tx := range bundle.Transactions {
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil || from != sender {
+			continue
+		}
+		if nonce < 0 || tx.Nonce() == uint64(nonce) {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	return first
+}
+
+func containsAny(s string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnose(t *testing.T) {
+	ctx := context.Background()
+	tx0, sender0 := newTestTx(t, 0)
+	tx1, sender1 := newTestTx(t, 7)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx0, tx1}}
+
+	relay := newTestRelay(t)
+	relay.handle(methodMevSimBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		return nil, &rpcError{Code: -32000, Message: fmt.Sprintf("nonce too low: address %s, tx: 7 state: 9", sender1.Hex())}
+	})
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+	res, err := fb.Simulate(ctx, bundle, 101)
+	require.EqualError(t, err, fmt.Sprintf("RPC error: nonce too low: address %s, tx: 7 state: 9 (code: -32000)", sender1.Hex()))
+	d := Diagnose(bundle, res, err)
+	require.Equal(t, FailureNonceTooLow, d.Category)
+	require.Equal(t, 1, d.TxIndex)
+	require.Equal(t, tx1.Hash().Hex(), d.TxHash)
+	require.Equal(t, -32000, d.Code)
+	require.Contains(t, d.Suggestion, "Resync")
+
+	cases := []struct {
+		name     string
+		res      *SimulateResponse
+		err      error
+		category FailureCategory
+		txIndex  int
+	}{
+		{
+			name:     "insufficient funds by sender",
+			err:      &rpcError{Message: fmt.Sprintf("insufficient funds for gas * price + value: address %s have 0 want 42001", sender0.Hex())},
+			category: FailureInsufficientFunds,
+			txIndex:  0,
+		},
+		{
+			name: "local simulator",
+			res: &SimulateResponse{
+				Error: fmt.Sprintf("transaction 1 (%s): max fee per gas less than block base fee: address %s", tx1.Hash().Hex(), sender1.Hex()),
+				Logs:  []TxLogResult{{GasUsed: "0x5208"}, {Error: "max fee per gas less than block base fee"}},
+			},
+			category: FailureFeeCapTooLow,
+			txIndex:  1,
+		},
+		{
+			name:     "by hash",
+			err:      fmt.Errorf("tx %s: intrinsic gas too low: have 20000, want 21000", tx0.Hash().Hex()),
+			category: FailureIntrinsicGas,
+			txIndex:  0,
+		},
+		{
+			name:     "nonce gap",
+			res:      &SimulateResponse{ExecError: "nonce too high"},
+			category: FailureNonceTooHigh,
+			txIndex:  -1,
+		},
+		{
+			name:     "revert",
+			err:      &SimulationRevertError{TxIndex: 1, TxHash: tx1.Hash().Hex(), Err: "insufficient funds"},
+			category: FailureReverted,
+			txIndex:  1,
+		},
+		{
+			name: "revert reason in message",
+			res: &SimulateResponse{
+				Error: fmt.Sprintf("transaction 1 (%s): execution reverted: insufficient funds", tx1.Hash().Hex()),
+				Logs:  []TxLogResult{{GasUsed: "0x5208"}, {GasUsed: "0x6000", Error: "execution reverted: insufficient funds"}},
+			},
+			category: FailureReverted,
+			txIndex:  1,
+		},
+		{
+			name:     "insufficient funds for transfer",
+			err:      &rpcError{Message: "insufficient funds for transfer"},
+			category: FailureInsufficientFunds,
+			txIndex:  -1,
+		},
+		{
+			name:     "bundle too large",
+			err:      fmt.Errorf("RPC error: %w", &rpcError{Message: "bundle too large"}),
+			category: FailureBundleTooLarge,
+			txIndex:  -1,
+		},
+		{
+			name:     "unknown builder",
+			err:      &rpcError{Message: "unknown builder: nobody"},
+			category: FailureUnknownBuilder,
+			txIndex:  -1,
+		},
+		{
+			name:     "unknown",
+			err:      errors.New("connection refused"),
+			category: FailureUnknown,
+			txIndex:  -1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := Diagnose(bundle, c.res, c.err)
+			require.NotNil(t, d)
+			require.Equal(t, c.category, d.Category)
+			require.Equal(t, c.txIndex, d.TxIndex)
+			if c.category == FailureUnknown {
+				require.Empty(t, d.Suggestion)
+			} else {
+				require.NotEmpty(t, d.Suggestion)
+			}
+		})
+	}
+
+	require.Nil(t, Diagnose(bundle, &SimulateResponse{Success: true}, nil))
+}
@@ -0,0 +1,203 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// EstimateMode selects how EstimateGasBundle obtains the gas used by each transaction.
+type EstimateMode string
+
+const (
+	// EstimateModeGasLimit uses the signed gas limits. No request is made.
+	EstimateModeGasLimit EstimateMode = "gas_limit"
+	// EstimateModeRelay simulates the bundle on the relay with eth_callBundle.
+	EstimateModeRelay EstimateMode = "relay"
+	// EstimateModeNode simulates the bundle on the Ethereum node with eth_simulateV1, its transactions in
+	// bundle order on top of each other's state, so an approval or deployment earlier in the bundle is seen
+	// by the transactions after it. Gas fees are not charged.
+	EstimateModeNode EstimateMode = "node"
+)
+
+// GasEstimate is the gas used by a bundle.
+type GasEstimate struct {
+	Mode EstimateMode
+	// GasUsed holds the gas used by each transaction, in bundle order.
+	GasUsed []uint64
+	Total   uint64
+	// RecommendedGasLimits holds GasUsed plus the safety margin, in bundle order.
+	RecommendedGasLimits []uint64
+	RecommendedTotal     uint64
+	// SafetyMargin is the percentage added on top of GasUsed.
+	SafetyMargin uint64
+	// BlobGas holds the blob gas of each transaction, in bundle order. Blob gas is fixed per blob and
+	// is not part of GasUsed.
+	BlobGas      []uint64
+	TotalBlobGas uint64
+}
+
+// estimateConfig holds the settings of a single EstimateGasBundle call.
+type estimateConfig struct {
+	mode         EstimateMode
+	safetyMargin uint64
+	block        uint64
+}
+
+// EstimateOption configures EstimateGasBundle.
+type EstimateOption func(*estimateConfig) error
+
+// WithEstimateMode selects how the gas used by each transaction is obtained.
+func WithEstimateMode(mode EstimateMode) EstimateOption {
+	return func(cfg *estimateConfig) error {
+		switch mode {
+		case EstimateModeGasLimit, EstimateModeRelay, EstimateModeNode:
+			cfg.mode = mode
+			return nil
+		default:
+			return fmt.Errorf("unknown estimate mode %q", mode)
+		}
+	}
+}
+
+// WithGasSafetyMargin sets the percentage added to the gas used to recommend gas limits. Defaults to 25.
+func WithGasSafetyMargin(percent uint64) EstimateOption {
+	return func(cfg *estimateConfig) error {
+		cfg.safetyMargin = percent
+		return nil
+	}
+}
+
+// WithEstimateBlock sets the block the relay simulates the bundle for. Defaults to the block after the latest one.
+func WithEstimateBlock(block uint64) EstimateOption {
+	return func(cfg *estimateConfig) error {
+		cfg.block = block
+		return nil
+	}
+}
+
+// AddGasMargin returns gas increased by percent, e.g. AddGasMargin(100_000, 25) is 125_000.
+func AddGasMargin(gas uint64, percent uint64) uint64 {
+	return gas + gas*percent/100
+}
+
+// estimateGasWithRelay simulates the bundle with eth_callBundle and returns the gas used by each transaction.
+func (f *flashbot) estimateGasWithRelay(ctx context.Context, bundle *Bundle, block uint64) ([]uint64, error) {
+	if block == 0 {
+		if f.ethC == nil {
+			return nil, fmt.Errorf("estimate block is required without an eth client: %w", ErrEthClientNotConfigured)
+		}
+		head, err := f.ethC.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block number: %w", err)
+		}
+		block = head + 1
+	}
+	resp, err := f.callBundle(ctx, bundle, block, "latest")
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(bundle.Transactions) {
+		return nil, fmt.Errorf("relay returned %d results for %d transactions", len(resp.Results), len(bundle.Transactions))
+	}
+	gasUsed := make([]uint64, len(resp.Results))
+	for i, res := range resp.Results {
+		if res.Error != "" && (i >= len(bundle.CanRevert) || !bundle.CanRevert[i]) {
+			return nil, fmt.Errorf("transaction %d failed in simulation: %s", i, res.Error)
+		}
+		gasUsed[i] = res.GasUsed
+	}
+	return gasUsed, nil
+}
+
+// callBundle simulates the bundle with eth_callBundle on top of stateBlock.
+// stateBlock: Hex-encoded block number or a block tag such as "latest".
+func (f *flashbot) callBundle(ctx context.Context, bundle *Bundle, block uint64, stateBlock string) (*callBundleResp, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.callBundle")
+	defer span.End()
+
+	txs, err := bundle.encodedTransactions()
+	if err != nil {
+		return nil, err
+	}
+	params := EthCallBundleParams{
+		Txs:              txs,
+		BlockNumber:      "0x" + strconv.FormatUint(block, 16),
+		StateBlockNumber: stateBlock,
+	}
+	var resp callBundleResp
+	if err := f.sendRPC(ctx, methodEthCallBundle, []interface{}{params}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// simulateCallResult is the outcome of a call in an eth_simulateV1 block.
+type simulateCallResult struct {
+	Status  hexutil.Uint64 `json:"status"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Error   *rpcError      `json:"error,omitempty"`
+}
+
+// estimateGasWithNode runs the bundle's transactions in order as calls of a single block with eth_simulateV1,
+// so each one sees the state left by the previous ones, and returns the gas used by each.
+// Nonces, fees and balances for gas are not validated, the gas limit of each call is the block's.
+func (f *flashbot) estimateGasWithNode(ctx context.Context, bundle *Bundle) ([]uint64, error) {
+	if f.ethC == nil {
+		return nil, ErrEthClientNotConfigured
+	}
+	calls := make([]map[string]interface{}, len(bundle.Transactions))
+	for i, tx := range bundle.Transactions {
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+		}
+		call := map[string]interface{}{
+			"from":  sender,
+			"value": (*hexutil.Big)(tx.Value()),
+			"input": hexutil.Bytes(tx.Data()),
+		}
+		if tx.To() != nil {
+			call["to"] = tx.To()
+		}
+		if len(tx.AccessList()) > 0 {
+			call["accessList"] = tx.AccessList()
+		}
+		if auths := tx.SetCodeAuthorizations(); len(auths) > 0 {
+			call["authorizationList"] = auths
+		}
+		if hashes := tx.BlobHashes(); len(hashes) > 0 {
+			call["blobVersionedHashes"] = hashes
+			call["maxFeePerBlobGas"] = (*hexutil.Big)(tx.BlobGasFeeCap())
+		}
+		calls[i] = call
+	}
+	opts := map[string]interface{}{
+		"blockStateCalls": []map[string]interface{}{{"calls": calls}},
+		"validation":      false,
+	}
+	var blocks []struct {
+		Calls []simulateCallResult `json:"calls"`
+	}
+	if err := f.ethC.Client().CallContext(ctx, &blocks, "eth_simulateV1", opts, "latest"); err != nil {
+		return nil, fmt.Errorf("failed to simulate bundle: %w", err)
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != len(bundle.Transactions) {
+		return nil, fmt.Errorf("node returned an unexpected simulation for %d transactions", len(bundle.Transactions))
+	}
+	gasUsed := make([]uint64, len(bundle.Transactions))
+	for i, call := range blocks[0].Calls {
+		if uint64(call.Status) != types.ReceiptStatusSuccessful && !bundle.canRevert(i) {
+			reason := "execution reverted"
+			if call.Error != nil {
+				reason = call.Error.Message
+			}
+			return nil, fmt.Errorf("transaction %d failed in simulation: %s", i, reason)
+		}
+		gasUsed[i] = uint64(call.GasUsed)
+	}
+	return gasUsed, nil
+}
@@ -0,0 +1,109 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateGasBundle(t *testing.T) {
+	ctx := context.Background()
+	relay := newTestRelay(t)
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+
+	tx1, _ := newTestTx(t, 0)
+	tx2, _ := newTestTx(t, 0)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx1, tx2}}
+
+	t.Run("gas limit", func(t *testing.T) {
+		est, err := fb.EstimateGasBundle(ctx, bundle)
+		require.NoError(t, err)
+		require.Equal(t, EstimateModeGasLimit, est.Mode)
+		require.Equal(t, uint64(42000), est.Total)
+		require.Equal(t, []uint64{26250, 26250}, est.RecommendedGasLimits)
+	})
+
+	t.Run("relay", func(t *testing.T) {
+		relay.handle(methodEthCallBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+			var p EthCallBundleParams
+			if err := json.Unmarshal(params[0], &p); err != nil {
+				return nil, invalidParams(t, err)
+			}
+			assert.Equal(t, "0x65", p.BlockNumber)
+			assert.Len(t, p.Txs, 2)
+			return callBundleResp{Results: []callBundleTxResult{{GasUsed: 21000}, {GasUsed: 30000}}}, nil
+		})
+		est, err := fb.EstimateGasBundle(ctx, bundle,
+			WithEstimateMode(EstimateModeRelay),
+			WithEstimateBlock(101),
+			WithGasSafetyMargin(10),
+		)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{21000, 30000}, est.GasUsed)
+		require.Equal(t, uint64(51000), est.Total)
+		require.Equal(t, uint64(56100), est.RecommendedTotal)
+	})
+
+	t.Run("relay revert", func(t *testing.T) {
+		relay.handle(methodEthCallBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+			return callBundleResp{Results: []callBundleTxResult{{GasUsed: 21000}, {GasUsed: 30000, Error: "execution reverted"}}}, nil
+		})
+		_, err := fb.EstimateGasBundle(ctx, bundle, WithEstimateMode(EstimateModeRelay), WithEstimateBlock(101))
+		require.Error(t, err)
+	})
+
+	_, err = fb.EstimateGasBundle(ctx, bundle, WithEstimateMode("unknown"))
+	require.Error(t, err)
+}
+
+func TestEstimateGasBundleNode(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	node.setHead(100)
+	fb, err := New(ctx, WithEthClient(ethC))
+	require.NoError(t, err)
+
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := common.HexToAddress("0x4bfD011E2bE77b57A42882f2e854a235a7D18646")
+	newCall := func(nonce uint64, input []byte) *types.Transaction {
+		tx, err := types.SignNewTx(pk, types.LatestSignerForChainID(big.NewInt(SepoliaChainID)), &types.DynamicFeeTx{
+			ChainID:   big.NewInt(SepoliaChainID),
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       100_000,
+			GasFeeCap: big.NewInt(2e9),
+			GasTipCap: big.NewInt(1e9),
+			Data:      input,
+		})
+		require.NoError(t, err)
+		return tx
+	}
+	approve, transferFrom := newCall(0, []byte{0x01}), newCall(1, []byte{0x02})
+
+	// The transfer only succeeds on top of the approval made before it in the bundle.
+	est, err := fb.EstimateGasBundle(ctx, &Bundle{Transactions: []*types.Transaction{approve, transferFrom}},
+		WithEstimateMode(EstimateModeNode))
+	require.NoError(t, err)
+	require.Equal(t, EstimateModeNode, est.Mode)
+	require.Equal(t, []uint64{46_000, 35_000}, est.GasUsed)
+	require.Equal(t, uint64(81_000), est.Total)
+
+	// In the other order the transfer reverts.
+	reversed := &Bundle{Transactions: []*types.Transaction{transferFrom, approve}}
+	_, err = fb.EstimateGasBundle(ctx, reversed, WithEstimateMode(EstimateModeNode))
+	require.ErrorContains(t, err, "transaction 0 failed in simulation: execution reverted: insufficient allowance")
+
+	reversed.CanRevert = []bool{true, false}
+	est, err = fb.EstimateGasBundle(ctx, reversed, WithEstimateMode(EstimateModeNode))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{23_000, 46_000}, est.GasUsed)
+}
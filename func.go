@@ -29,26 +29,31 @@ func (f *flashbot) Simulate(ctx context.Context, bundle *Bundle, targetBlock uin
 		span.SetStatus(codes.Error, "bundle is empty")
 		return nil, fmt.Errorf("bundle cannot be empty")
 	}
+	if f.simCache == nil {
+		return f.simulate(ctx, bundle, targetBlock, opts...)
+	}
 
-	// Convert transactions to hex strings
-	body := make([]mevSendBundleBodyItem, 0, len(bundle.Transactions))
-	for i, tx := range bundle.Transactions {
-		bs, err := tx.MarshalBinary()
-		if err != nil {
-			span.SetStatus(codes.Error, err.Error())
-			span.RecordError(err)
-			return nil, fmt.Errorf("failed to encode transaction: %w", err)
-		}
-		txHex := hexutil.Encode(bs)
-		canRevert := false
-		if i < len(bundle.CanRevert) {
-			canRevert = bundle.CanRevert[i]
-		}
-		body = append(body, mevSendBundleBodyItem{
-			Tx:        &txHex,
-			CanRevert: &canRevert,
-		})
+	key, err := simulationKey(bundle, targetBlock, opts)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
 	}
+	res, err := f.simCache.do(ctx, key, func(ctx context.Context) (*SimulateResponse, error) {
+		return f.simulate(ctx, bundle, targetBlock, opts...)
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return res, err
+	}
+	span.SetStatus(codes.Ok, "simulation completed successfully")
+	return res, nil
+}
+
+// simulate runs the bundle on the configured simulator, or the relay.
+func (f *flashbot) simulate(ctx context.Context, bundle *Bundle, targetBlock uint64, opts ...BundleOption) (*SimulateResponse, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.simulate")
+	defer span.End()
 
 	// Build the mev_simBundle params
 	var blockHex string
@@ -62,7 +67,6 @@ func (f *flashbot) Simulate(ctx context.Context, bundle *Bundle, targetBlock uin
 		Inclusion: mevSendBundleInclusion{
 			Block: blockHex,
 		},
-		Body: body,
 	}
 	// Apply options
 	for _, opt := range opts {
@@ -74,13 +78,51 @@ func (f *flashbot) Simulate(ctx context.Context, bundle *Bundle, targetBlock uin
 		}
 	}
 
+	// A custom simulator replaces the relay, only the simulation overrides apply to it.
+	if f.simulator != nil {
+		var res *SimulateResponse
+		var err error
+		if sim, ok := f.simulator.(OverridableSimulator); ok {
+			res, err = sim.SimulateWithOverrides(ctx, bundle, targetBlock, params.simOptions)
+		} else if params.simOptions != nil {
+			err = ErrSimOverridesUnsupported
+		} else {
+			res, err = f.simulator.Simulate(ctx, bundle, targetBlock)
+		}
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
+		if err := f.decodeReverts(bundle, res); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return res, err
+		}
+		span.SetStatus(codes.Ok, "simulation completed successfully")
+		return res, nil
+	}
+
+	// Convert transactions to hex strings
+	body, err := bundle.bodyItems()
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	params.Body = body
+
+	rpcParams := []interface{}{params}
+	if params.simOptions != nil {
+		rpcParams = append(rpcParams, params.simOptions)
+	}
+
 	// Create JSON-RPC request
 	reqID := rand.Intn(1000000)
 	reqBody := rpcReq{
 		JsonRpc: jsonRPCVersion,
 		Id:      reqID,
 		Method:  methodMevSimBundle,
-		Params:  []interface{}{params},
+		Params:  rpcParams,
 	}
 
 	httpReq, err := f.newRequest(ctx, &reqBody)
@@ -127,13 +169,17 @@ func (f *flashbot) Simulate(ctx context.Context, bundle *Bundle, targetBlock uin
 
 	if rpcResp.Error != nil {
 		span.SetStatus(codes.Error, rpcResp.Error.Message)
-		return rpcResp.Result, fmt.Errorf("RPC error: %s (code: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+		return rpcResp.Result, fmt.Errorf("RPC error: %w", rpcResp.Error)
 	}
 
 	if rpcResp.Result == nil {
 		span.SetStatus(codes.Error, "empty result")
 		return nil, fmt.Errorf("empty result from relay")
 	}
+	if err := f.decodeReverts(bundle, rpcResp.Result); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return rpcResp.Result, err
+	}
 	span.SetStatus(codes.Ok, "simulation completed successfully")
 	return rpcResp.Result, nil
 }
@@ -143,30 +189,13 @@ func (f *flashbot) Simulate(ctx context.Context, bundle *Bundle, targetBlock uin
 func (f *flashbot) Broadcast(ctx context.Context, bundle *Bundle, targetBlock uint64, opts ...BundleOption) (*BroadcastResponse, error) {
 	ctx, span := f.tracer.Start(ctx, "flashbot.Broadcast")
 	defer span.End()
-	_, err := f.Simulate(ctx, bundle, targetBlock)
+
+	// Convert transactions to hex strings
+	body, err := bundle.bodyItems()
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
-		return nil, fmt.Errorf("failed to simulate bundle: %w", err)
-	}
-	// Convert transactions to hex strings
-	body := make([]mevSendBundleBodyItem, 0, len(bundle.Transactions))
-	for i, tx := range bundle.Transactions {
-		bs, err := tx.MarshalBinary()
-		if err != nil {
-			span.SetStatus(codes.Error, err.Error())
-			span.RecordError(err)
-			return nil, fmt.Errorf("failed to encode transaction: %w", err)
-		}
-		txHex := hexutil.Encode(bs)
-		canRevert := false
-		if i < len(bundle.CanRevert) {
-			canRevert = bundle.CanRevert[i]
-		}
-		body = append(body, mevSendBundleBodyItem{
-			Tx:        &txHex,
-			CanRevert: &canRevert,
-		})
+		return nil, err
 	}
 
 	// Build the mev_simBundle params
@@ -176,7 +205,7 @@ func (f *flashbot) Broadcast(ctx context.Context, bundle *Bundle, targetBlock ui
 	} else {
 		blockHex = "0x" + strconv.FormatUint(targetBlock, 16)
 	}
-	maxBlock := "0x" + strconv.FormatUint(targetBlock+30, 16)
+	maxBlock := "0x" + strconv.FormatUint(targetBlock+defaultBundleExpirationBlocks, 16)
 	params := mevSimBundleParams{
 		Version: "v0.1",
 		Inclusion: mevSendBundleInclusion{
@@ -196,6 +225,22 @@ func (f *flashbot) Broadcast(ctx context.Context, bundle *Bundle, targetBlock ui
 		}
 	}
 
+	// Simulate the bundle and check it against the pre-flight policies
+	if !params.skipPreflight && len(f.preflight) > 0 {
+		// A failed simulation is left to the policies, RequireSimulationSuccess rejects it.
+		res, err := f.Simulate(ctx, bundle, targetBlock, opts...)
+		if err != nil && !isSimulationFailure(err) {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to simulate bundle: %w", err)
+		}
+		if err := runPreflight(ctx, f.preflight, bundle, res); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
 	// Create JSON-RPC request
 	reqID := rand.Intn(1000000)
 	reqBody := rpcReq{
@@ -233,7 +278,6 @@ func (f *flashbot) Broadcast(ctx context.Context, bundle *Bundle, targetBlock ui
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
-	fmt.Println(string(bs))
 	err = resp.Body.Close()
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
@@ -250,7 +294,7 @@ func (f *flashbot) Broadcast(ctx context.Context, bundle *Bundle, targetBlock ui
 
 	if rpcResp.Error != nil {
 		span.SetStatus(codes.Error, rpcResp.Error.Message)
-		return rpcResp.Result, fmt.Errorf("RPC error: %s (code: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+		return rpcResp.Result, fmt.Errorf("RPC error: %w", rpcResp.Error)
 	}
 
 	if rpcResp.Result == nil {
@@ -275,9 +319,29 @@ func (f *flashbot) SendPrivateTransaction(ctx context.Context, signedTxHex strin
 // You can implement this to return a "fast" price for aggressive inclusion.
 // tip is the current optimal priority fee (bribe) based on network congestion.
 // This helps you calculate the `minerBribe` dynamically rather than hardcoding 2 Gwei.
-func (f *flashbot) GetGasPrice(ctx context.Context) (gasPrice *big.Int, tip *big.Int, err error) {
+// strategy: (Optional) Overrides the client's gas strategy for this call. Without any strategy
+// the node's eth_gasPrice and eth_maxPriorityFeePerGas suggestions are returned.
+func (f *flashbot) GetGasPrice(ctx context.Context, strategy ...GasStrategy) (gasPrice *big.Int, tip *big.Int, err error) {
 	ctx, span := f.tracer.Start(ctx, "flashbot.GetGasPrice")
 	defer span.End()
+	if f.ethC == nil {
+		span.SetStatus(codes.Error, ErrEthClientNotConfigured.Error())
+		return nil, nil, ErrEthClientNotConfigured
+	}
+	s := f.gasStrategy
+	if len(strategy) > 0 && strategy[0] != nil {
+		s = strategy[0]
+	}
+	if s != nil {
+		gasPrice, tip, err = f.suggestGasPrice(ctx, s)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, nil, err
+		}
+		span.SetStatus(codes.Ok, "gas price retrieved successfully")
+		return gasPrice, tip, nil
+	}
 	gasPrice, err = f.ethC.SuggestGasPrice(ctx)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
@@ -296,15 +360,65 @@ func (f *flashbot) GetGasPrice(ctx context.Context) (gasPrice *big.Int, tip *big
 
 // EstimateGasBundle calculates the gas units required for the entire bundle.
 // This is useful for calculating exactly how much "sponsorship" ETH to send the user.
-func (f *flashbot) EstimateGasBundle(ctx context.Context, bundle *Bundle) (uint64, error) {
+// By default it adds up the signed gas limits; WithEstimateMode switches to a simulation
+// through the relay (eth_callBundle) or the Ethereum node (eth_simulateV1).
+func (f *flashbot) EstimateGasBundle(ctx context.Context, bundle *Bundle, opts ...EstimateOption) (*GasEstimate, error) {
 	ctx, span := f.tracer.Start(ctx, "flashbot.EstimateGasBundle")
 	defer span.End()
-	totalGas := uint64(0)
-	for _, tx := range bundle.Transactions {
-		totalGas += tx.Gas()
+
+	if len(bundle.Transactions) == 0 {
+		span.SetStatus(codes.Error, "bundle is empty")
+		return nil, fmt.Errorf("bundle cannot be empty")
+	}
+	cfg := estimateConfig{
+		mode:         EstimateModeGasLimit,
+		safetyMargin: defaultGasSafetyMargin,
+	}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+
+	var gasUsed []uint64
+	var err error
+	switch cfg.mode {
+	case EstimateModeGasLimit:
+		gasUsed = make([]uint64, len(bundle.Transactions))
+		for i, tx := range bundle.Transactions {
+			gasUsed[i] = tx.Gas()
+		}
+	case EstimateModeRelay:
+		gasUsed, err = f.estimateGasWithRelay(ctx, bundle, cfg.block)
+	case EstimateModeNode:
+		gasUsed, err = f.estimateGasWithNode(ctx, bundle)
+	default:
+		err = fmt.Errorf("unknown estimate mode %q", cfg.mode)
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	estimate := &GasEstimate{
+		Mode:                 cfg.mode,
+		GasUsed:              gasUsed,
+		RecommendedGasLimits: make([]uint64, len(gasUsed)),
+		SafetyMargin:         cfg.safetyMargin,
+		BlobGas:              make([]uint64, len(gasUsed)),
+	}
+	for i, gas := range gasUsed {
+		estimate.Total += gas
+		estimate.RecommendedGasLimits[i] = AddGasMargin(gas, cfg.safetyMargin)
+		estimate.RecommendedTotal += estimate.RecommendedGasLimits[i]
+		estimate.BlobGas[i] = bundle.Transactions[i].BlobGas()
+		estimate.TotalBlobGas += estimate.BlobGas[i]
 	}
 	span.SetStatus(codes.Ok, "gas units calculated successfully")
-	return totalGas, nil
+	return estimate, nil
 }
 
 // --- Utilities ---
@@ -321,6 +435,100 @@ func (f *flashbot) GetBundleStats(ctx context.Context, bundleHash string, blockN
 }
 
 // INTERNAL METHODS
+
+// sendRPC sends a signed JSON-RPC request to the relay and decodes its result into result.
+func (f *flashbot) sendRPC(ctx context.Context, method method, params []interface{}, result interface{}) error {
+	ctx, span := f.tracer.Start(ctx, "flashbot.sendRPC")
+	defer span.End()
+
+	reqBody := rpcReq{
+		JsonRpc: jsonRPCVersion,
+		Id:      rand.Intn(1000000),
+		Method:  method,
+		Params:  params,
+	}
+	httpReq, err := f.newRequest(ctx, &reqBody)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	err = resp.Body.Close()
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return fmt.Errorf("failed to close response body: %w", err)
+	}
+
+	var rpcResp struct {
+		Id     int             `json:"id"`
+		Result json.RawMessage `json:"result,omitempty"`
+		Error  *rpcError       `json:"error,omitempty"`
+	}
+	err = json.Unmarshal(bs, &rpcResp)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		span.SetStatus(codes.Error, rpcResp.Error.Message)
+		return fmt.Errorf("RPC error: %w", rpcResp.Error)
+	}
+	if len(rpcResp.Result) == 0 || string(rpcResp.Result) == "null" {
+		span.SetStatus(codes.Error, "empty result")
+		return fmt.Errorf("empty result from relay")
+	}
+	err = json.Unmarshal(rpcResp.Result, result)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+	span.SetStatus(codes.Ok, "request completed successfully")
+	return nil
+}
+
+// parseBigInt parses a relay quantity, which is hex-encoded with a 0x prefix or plain decimal.
+// An empty string is zero.
+func parseBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return new(big.Int), nil
+	}
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return v, nil
+}
+
+// getChainID returns the configured chain ID, falling back to the Ethereum client when none was set.
+func (f *flashbot) getChainID(ctx context.Context) (*big.Int, error) {
+	if f.chainID != 0 {
+		return new(big.Int).SetUint64(f.chainID), nil
+	}
+	if f.ethC == nil {
+		return nil, ErrEthClientNotConfigured
+	}
+	chainID, err := f.ethC.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	return chainID, nil
+}
+
 func (f *flashbot) newRequest(ctx context.Context, req *rpcReq) (*http.Request, error) {
 	ctx, span := f.tracer.Start(ctx, "flashbot.newRequest")
 	defer span.End()
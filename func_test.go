@@ -23,7 +23,7 @@ func TestSimulate(t *testing.T) {
 	const ERC20TokenAddress = "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"
 	const ERC20Destanation = "0x4bfD011E2bE77b57A42882f2e854a235a7D18646"
 
-	fb, err := New(context.Background(), WithChainID(SepoliaChainID), WithRelayURL(SepoliaRelayURL))
+	fb, err := New(context.Background(), WithChainID(SepoliaChainID), WithRelayURL(SepoliaRelayURL), WithEthClient(ethC))
 	require.NoError(t, err)
 	ethWallet, err := crypto.HexToECDSA(os.Getenv("ETH_WALLET_PK"))
 	require.NoError(t, err)
@@ -52,7 +52,7 @@ func TestSimulate(t *testing.T) {
 		transferData, err := abi.Pack("transfer", common.HexToAddress(ERC20Destanation), big.NewInt(1))
 		require.NoError(t, err)
 
-		ercWalletNounce, err := ethC.PendingNonceAt(context.Background(), crypto.PubkeyToAddress(ercWallet.PublicKey))
+		ercWalletNounce, err := fb.NonceManager().Reserve(context.Background(), crypto.PubkeyToAddress(ercWallet.PublicKey), 1)
 		require.NoError(t, err)
 
 		ETAGas, err := ethC.EstimateGas(context.Background(), ethereum.CallMsg{
@@ -61,7 +61,7 @@ func TestSimulate(t *testing.T) {
 			Data: transferData,
 		})
 		require.NoError(t, err)
-		gas := ETAGas * 125 / 100
+		gas := AddGasMargin(ETAGas, 25)
 
 		// Calculate gas cost for tx2: gasLimit * gasPrice
 		tx2GasCost = new(big.Int).Mul(big.NewInt(int64(gas)), gasPrice)
@@ -84,7 +84,7 @@ func TestSimulate(t *testing.T) {
 
 	// tx 1 - sends ETH to ercWallet to cover gas costs for tx2
 	{
-		nonce, err := ethC.NonceAt(context.Background(), crypto.PubkeyToAddress(ethWallet.PublicKey), nil)
+		nonce, err := fb.NonceManager().Reserve(context.Background(), crypto.PubkeyToAddress(ethWallet.PublicKey), 1)
 		require.NoError(t, err)
 
 		To := crypto.PubkeyToAddress(ercWallet.PublicKey)
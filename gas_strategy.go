@@ -0,0 +1,121 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// GasStrategy turns recent fee history into a fee cap and priority fee.
+type GasStrategy interface {
+	// Name identifies the strategy in traces and logs.
+	Name() string
+	// RewardPercentiles are the eth_feeHistory reward percentiles the strategy needs.
+	RewardPercentiles() []float64
+	// Suggest computes the fee cap and tip from the fee history of the latest blocks.
+	// history.BaseFee holds one more entry than the other fields: the base fee of the next block.
+	Suggest(history *ethereum.FeeHistory) (gasFeeCap *big.Int, tip *big.Int, err error)
+}
+
+// percentileStrategy tips the average reward paid at a percentile of recent blocks
+// and caps fees at a multiple of the next block's base fee plus that tip.
+type percentileStrategy struct {
+	name              string
+	percentile        float64
+	baseFeeMultiplier float64
+}
+
+var _ GasStrategy = (*percentileStrategy)(nil)
+
+// SlowGasStrategy tips the 10th percentile and only covers one block of base fee increase.
+func SlowGasStrategy() GasStrategy {
+	return &percentileStrategy{name: "slow", percentile: 10, baseFeeMultiplier: 1.125}
+}
+
+// StandardGasStrategy tips the median and covers two blocks of base fee increase.
+func StandardGasStrategy() GasStrategy {
+	return &percentileStrategy{name: "standard", percentile: 50, baseFeeMultiplier: 1.25}
+}
+
+// FastGasStrategy tips the 75th percentile and covers about four blocks of base fee increase.
+func FastGasStrategy() GasStrategy {
+	return &percentileStrategy{name: "fast", percentile: 75, baseFeeMultiplier: 1.6}
+}
+
+// AggressiveGasStrategy tips the 95th percentile and covers about six blocks of base fee increase.
+func AggressiveGasStrategy() GasStrategy {
+	return &percentileStrategy{name: "aggressive", percentile: 95, baseFeeMultiplier: 2}
+}
+
+// PercentileGasStrategy tips the given reward percentile (0-100) of recent blocks and caps fees at
+// baseFeeMultiplier times the next base fee plus the tip.
+func PercentileGasStrategy(percentile float64, baseFeeMultiplier float64) (GasStrategy, error) {
+	if percentile < 0 || percentile > 100 {
+		return nil, fmt.Errorf("percentile must be within [0, 100], got %v", percentile)
+	}
+	if baseFeeMultiplier < 1 {
+		return nil, fmt.Errorf("base fee multiplier must be at least 1, got %v", baseFeeMultiplier)
+	}
+	return &percentileStrategy{
+		name:              fmt.Sprintf("p%v", percentile),
+		percentile:        percentile,
+		baseFeeMultiplier: baseFeeMultiplier,
+	}, nil
+}
+
+func (s *percentileStrategy) Name() string {
+	return s.name
+}
+
+func (s *percentileStrategy) RewardPercentiles() []float64 {
+	return []float64{s.percentile}
+}
+
+func (s *percentileStrategy) Suggest(history *ethereum.FeeHistory) (*big.Int, *big.Int, error) {
+	if history == nil || len(history.BaseFee) == 0 {
+		return nil, nil, fmt.Errorf("fee history is empty")
+	}
+	nextBaseFee := history.BaseFee[len(history.BaseFee)-1]
+
+	// Empty blocks report a zero reward, which says nothing about what it takes to get in.
+	tip := new(big.Int)
+	var samples int64
+	for _, rewards := range history.Reward {
+		if len(rewards) == 0 || rewards[0] == nil || rewards[0].Sign() == 0 {
+			continue
+		}
+		tip.Add(tip, rewards[0])
+		samples++
+	}
+	if samples > 0 {
+		tip.Div(tip, big.NewInt(samples))
+	}
+
+	feeCap, _ := new(big.Float).Mul(new(big.Float).SetInt(nextBaseFee), big.NewFloat(s.baseFeeMultiplier)).Int(nil)
+	feeCap.Add(feeCap, tip)
+	return feeCap, tip, nil
+}
+
+// suggestGasPrice runs a gas strategy against the fee history of the latest blocks.
+func (f *flashbot) suggestGasPrice(ctx context.Context, strategy GasStrategy) (*big.Int, *big.Int, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.suggestGasPrice")
+	defer span.End()
+
+	history, err := f.ethC.FeeHistory(ctx, feeHistoryBlocks, nil, strategy.RewardPercentiles())
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	gasFeeCap, tip, err := strategy.Suggest(history)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("gas strategy %s failed: %w", strategy.Name(), err)
+	}
+	span.SetStatus(codes.Ok, "gas price suggested by "+strategy.Name())
+	return gasFeeCap, tip, nil
+}
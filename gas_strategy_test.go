@@ -0,0 +1,39 @@
+package flashbot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentileGasStrategy(t *testing.T) {
+	history := &ethereum.FeeHistory{
+		OldestBlock: big.NewInt(100),
+		Reward: [][]*big.Int{
+			{big.NewInt(1e9)},
+			{big.NewInt(0)}, // empty block, ignored
+			{big.NewInt(3e9)},
+		},
+		BaseFee:      []*big.Int{big.NewInt(10e9), big.NewInt(11e9), big.NewInt(12e9), big.NewInt(20e9)},
+		GasUsedRatio: []float64{0.5, 0, 0.9},
+	}
+
+	feeCap, tip, err := AggressiveGasStrategy().Suggest(history)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(2e9), tip)
+	require.Equal(t, big.NewInt(42e9), feeCap) // 2 * next base fee + tip
+
+	custom, err := PercentileGasStrategy(90, 1)
+	require.NoError(t, err)
+	require.Equal(t, []float64{90}, custom.RewardPercentiles())
+	feeCap, _, err = custom.Suggest(history)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(22e9), feeCap)
+
+	_, err = PercentileGasStrategy(101, 1)
+	require.Error(t, err)
+	_, _, err = SlowGasStrategy().Suggest(&ethereum.FeeHistory{})
+	require.Error(t, err)
+}
@@ -3,7 +3,9 @@ package flashbot
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -19,12 +21,24 @@ type flashbot struct {
 	relayURL string
 	chainID  uint64
 
-	builders []string
-	pk       *ecdsa.PrivateKey
-	ethC     *ethclient.Client
-	client   *http.Client
+	builders     []string
+	pk           *ecdsa.PrivateKey
+	ethC         *ethclient.Client
+	client       *http.Client
+	pollInterval time.Duration
+
+	builderRegistry BuilderRegistry
+	gasStrategy     GasStrategy
+	nonces          *NonceManager
+	simulator       Simulator
+	abis            *ABIRegistry
+	preflight       []PreflightPolicy
+	simCache        *SimulationCache
 }
 
+// ErrEthClientNotConfigured is returned by methods that need an Ethereum node when no client was set with WithEthClient.
+var ErrEthClientNotConfigured = errors.New("eth client is not configured")
+
 var _ IFlashbot = (*flashbot)(nil)
 
 func New(ctx context.Context, opts ...Option) (IFlashbot, error) {
@@ -51,6 +65,10 @@ func (f *flashbot) setDefaults(ctx context.Context) error {
 	f.logger = logrus.StandardLogger()
 	f.client = http.DefaultClient
 	f.relayURL = MainnetRelayURL
+	f.pollInterval = defaultPollInterval
+	f.builderRegistry = DefaultBuilderRegistry()
+	f.abis = DefaultABIRegistry()
+	f.preflight = DefaultPreflight()
 	f.pk, err = crypto.GenerateKey()
 	if err != nil {
 		return err
@@ -61,5 +79,18 @@ func (f *flashbot) setDefaults(ctx context.Context) error {
 func (f *flashbot) init(ctx context.Context) error {
 	_, span := f.tracer.Start(ctx, "flashbot.init")
 	defer span.End()
+	if f.nonces == nil && f.ethC != nil {
+		f.nonces = NewNonceManager(f.ethC, 0)
+	}
 	return nil
 }
+
+// ABIRegistry returns the ABI registry of the client.
+func (f *flashbot) ABIRegistry() *ABIRegistry {
+	return f.abis
+}
+
+// NonceManager returns the nonce manager of the client.
+func (f *flashbot) NonceManager() *NonceManager {
+	return f.nonces
+}
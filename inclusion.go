@@ -0,0 +1,228 @@
+package flashbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// InclusionStatus is the final outcome of waiting for a bundle to land on chain.
+type InclusionStatus string
+
+const (
+	// InclusionStatusIncluded means every transaction of the bundle landed in the same block.
+	InclusionStatusIncluded InclusionStatus = "included"
+	// InclusionStatusPartiallyIncluded means only some of the bundle's transactions landed, or they landed in different blocks.
+	InclusionStatusPartiallyIncluded InclusionStatus = "partially_included"
+	// InclusionStatusExpired means none of the bundle's transactions landed before maxBlock.
+	InclusionStatusExpired InclusionStatus = "expired"
+	// InclusionStatusReplaced means a nonce used by the bundle was consumed by some other transaction.
+	InclusionStatusReplaced InclusionStatus = "replaced"
+)
+
+// InclusionResult describes where (and whether) a bundle landed.
+type InclusionResult struct {
+	Status InclusionStatus
+	// BlockNumber and BlockHash identify the block holding the first landed transaction of the bundle.
+	BlockNumber uint64
+	BlockHash   common.Hash
+	// Transactions holds one entry per bundle transaction, in bundle order.
+	Transactions []TxInclusion
+}
+
+// TxInclusion describes the on-chain state of a single bundle transaction.
+type TxInclusion struct {
+	TxHash   common.Hash
+	Included bool
+	// Replaced is true when the transaction's nonce was consumed by a different transaction.
+	Replaced    bool
+	BlockNumber uint64
+	BlockHash   common.Hash
+	// Index is the position of the transaction within its block.
+	Index uint
+}
+
+// WaitForInclusion polls the Ethereum node until the bundle lands, one of its nonces is consumed
+// by another transaction, or the chain passes maxBlock.
+// maxBlock: The last block the bundle is valid for. 0 means the current head plus the default Broadcast expiration.
+// The nonces of an expired bundle are released in the client's nonce manager, and the senders of a replaced
+// bundle are resynced from the chain.
+func (f *flashbot) WaitForInclusion(ctx context.Context, bundle *Bundle, maxBlock uint64) (*InclusionResult, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.WaitForInclusion")
+	defer span.End()
+
+	if f.ethC == nil {
+		span.SetStatus(codes.Error, ErrEthClientNotConfigured.Error())
+		return nil, ErrEthClientNotConfigured
+	}
+	if len(bundle.Transactions) == 0 {
+		span.SetStatus(codes.Error, "bundle is empty")
+		return nil, fmt.Errorf("bundle cannot be empty")
+	}
+
+	senders := make([]common.Address, len(bundle.Transactions))
+	result := &InclusionResult{
+		Transactions: make([]TxInclusion, len(bundle.Transactions)),
+	}
+	for i, tx := range bundle.Transactions {
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+		}
+		senders[i] = sender
+		result.Transactions[i].TxHash = tx.Hash()
+	}
+
+	if maxBlock == 0 {
+		head, err := f.ethC.BlockNumber(ctx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to get block number: %w", err)
+		}
+		maxBlock = head + defaultBundleExpirationBlocks
+	}
+
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+	for {
+		done, err := f.checkInclusion(ctx, bundle, senders, maxBlock, result)
+		if err != nil {
+			if ctx.Err() != nil {
+				span.SetStatus(codes.Error, ctx.Err().Error())
+				return nil, ctx.Err()
+			}
+			// Node errors are usually transient, keep polling until the context gives up.
+			f.logger.WithError(err).Warn("failed to check bundle inclusion")
+		} else if done {
+			f.settleNonces(ctx, bundle, senders, result.Status)
+			span.SetStatus(codes.Ok, string(result.Status))
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			span.SetStatus(codes.Error, ctx.Err().Error())
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkInclusion refreshes result with the current chain state and reports whether a final outcome was reached.
+func (f *flashbot) checkInclusion(ctx context.Context, bundle *Bundle, senders []common.Address, maxBlock uint64, result *InclusionResult) (bool, error) {
+	// The head is read before the receipts, so every transaction mined at or below it must already have a receipt.
+	head, err := f.ethC.BlockNumber(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	included, replaced := 0, 0
+	for i, tx := range bundle.Transactions {
+		txInc := &result.Transactions[i]
+		if !txInc.Included {
+			receipt, err := f.ethC.TransactionReceipt(ctx, tx.Hash())
+			switch {
+			case errors.Is(err, ethereum.NotFound):
+			case err != nil:
+				return false, fmt.Errorf("failed to get receipt of transaction %d: %w", i, err)
+			default:
+				txInc.Included = true
+				txInc.Replaced = false
+				txInc.BlockNumber = receipt.BlockNumber.Uint64()
+				txInc.BlockHash = receipt.BlockHash
+				txInc.Index = receipt.TransactionIndex
+			}
+		}
+		if txInc.Included {
+			included++
+			continue
+		}
+
+		nonce, err := f.ethC.NonceAt(ctx, senders[i], new(big.Int).SetUint64(head))
+		if err != nil {
+			return false, fmt.Errorf("failed to get nonce of %s: %w", senders[i].Hex(), err)
+		}
+		txInc.Replaced = nonce > tx.Nonce()
+		if txInc.Replaced {
+			replaced++
+		}
+	}
+
+	for _, txInc := range result.Transactions {
+		if txInc.Included {
+			result.BlockNumber = txInc.BlockNumber
+			result.BlockHash = txInc.BlockHash
+			break
+		}
+	}
+
+	switch {
+	case included == len(bundle.Transactions):
+		result.Status = InclusionStatusIncluded
+		for _, txInc := range result.Transactions {
+			if txInc.BlockHash != result.BlockHash {
+				result.Status = InclusionStatusPartiallyIncluded
+				break
+			}
+		}
+		return true, nil
+	case included > 0 && (replaced > 0 || head >= maxBlock):
+		result.Status = InclusionStatusPartiallyIncluded
+		return true, nil
+	case included == 0 && replaced > 0:
+		result.Status = InclusionStatusReplaced
+		return true, nil
+	case head >= maxBlock:
+		result.Status = InclusionStatusExpired
+		return true, nil
+	}
+	return false, nil
+}
+
+// settleNonces updates the nonce manager with the final outcome of a bundle, for the nonces of its senders
+// and of the authorities of its set-code authorizations.
+func (f *flashbot) settleNonces(ctx context.Context, bundle *Bundle, senders []common.Address, status InclusionStatus) {
+	if f.nonces == nil {
+		return
+	}
+	nonces := consumedNonces(bundle, senders)
+	switch status {
+	case InclusionStatusExpired:
+		for account, used := range nonces {
+			f.nonces.Release(account, used...)
+		}
+	case InclusionStatusReplaced, InclusionStatusPartiallyIncluded:
+		for account := range nonces {
+			if err := f.nonces.Resync(ctx, account); err != nil {
+				f.logger.WithError(err).Warn("failed to resync sender nonces")
+			}
+		}
+	}
+}
+
+// consumedNonces returns the nonces the bundle uses per account: the nonce of every transaction, and the
+// nonce of every set-code authorization whose authority can be recovered.
+func consumedNonces(bundle *Bundle, senders []common.Address) map[common.Address][]uint64 {
+	nonces := make(map[common.Address][]uint64)
+	for i, tx := range bundle.Transactions {
+		nonces[senders[i]] = append(nonces[senders[i]], tx.Nonce())
+		for _, auth := range tx.SetCodeAuthorizations() {
+			authority, err := auth.Authority()
+			if err != nil {
+				continue
+			}
+			nonces[authority] = append(nonces[authority], auth.Nonce)
+		}
+	}
+	return nonces
+}
@@ -0,0 +1,94 @@
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTx(t *testing.T, nonce uint64) (*types.Transaction, common.Address) {
+	t.Helper()
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := common.HexToAddress("0x4bfD011E2bE77b57A42882f2e854a235a7D18646")
+	tx, err := types.SignNewTx(pk, types.LatestSignerForChainID(big.NewInt(SepoliaChainID)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(SepoliaChainID),
+		Nonce:     nonce,
+		To:        &to,
+		Value:     big.NewInt(1),
+		Gas:       21000,
+		GasFeeCap: big.NewInt(2e9),
+		GasTipCap: big.NewInt(1e9),
+	})
+	require.NoError(t, err)
+	return tx, crypto.PubkeyToAddress(pk.PublicKey)
+}
+
+func TestWaitForInclusion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("included", func(t *testing.T) {
+		node, ethC := newTestNode(t)
+		fb, err := New(ctx, WithEthClient(ethC), WithPollInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		tx1, _ := newTestTx(t, 0)
+		tx2, _ := newTestTx(t, 3)
+		node.setHead(100)
+		node.include(tx1, 100, 4)
+		node.include(tx2, 100, 5)
+
+		res, err := fb.WaitForInclusion(ctx, &Bundle{Transactions: []*types.Transaction{tx1, tx2}}, 110)
+		require.NoError(t, err)
+		require.Equal(t, InclusionStatusIncluded, res.Status)
+		require.Equal(t, uint64(100), res.BlockNumber)
+		require.Equal(t, uint(5), res.Transactions[1].Index)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		node, ethC := newTestNode(t)
+		fb, err := New(ctx, WithEthClient(ethC), WithPollInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		tx, _ := newTestTx(t, 0)
+		node.setHead(111)
+
+		res, err := fb.WaitForInclusion(ctx, &Bundle{Transactions: []*types.Transaction{tx}}, 110)
+		require.NoError(t, err)
+		require.Equal(t, InclusionStatusExpired, res.Status)
+	})
+
+	t.Run("replaced", func(t *testing.T) {
+		node, ethC := newTestNode(t)
+		fb, err := New(ctx, WithEthClient(ethC), WithPollInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		tx, sender := newTestTx(t, 7)
+		node.setHead(100)
+		node.nonces[sender] = 8
+
+		res, err := fb.WaitForInclusion(ctx, &Bundle{Transactions: []*types.Transaction{tx}}, 110)
+		require.NoError(t, err)
+		require.Equal(t, InclusionStatusReplaced, res.Status)
+		require.True(t, res.Transactions[0].Replaced)
+	})
+
+	t.Run("partially included", func(t *testing.T) {
+		node, ethC := newTestNode(t)
+		fb, err := New(ctx, WithEthClient(ethC), WithPollInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		tx1, _ := newTestTx(t, 0)
+		tx2, _ := newTestTx(t, 0)
+		node.setHead(110)
+		node.include(tx1, 105, 0)
+
+		res, err := fb.WaitForInclusion(ctx, &Bundle{Transactions: []*types.Transaction{tx1, tx2}}, 110)
+		require.NoError(t, err)
+		require.Equal(t, InclusionStatusPartiallyIncluded, res.Status)
+		require.Equal(t, uint64(105), res.BlockNumber)
+	})
+}
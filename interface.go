@@ -3,6 +3,9 @@ package flashbot
 import (
 	"context"
 	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // IFlashBot defines the standard behavior for a MEV/Flashbots client.
@@ -13,10 +16,35 @@ type IFlashbot interface {
 	// This should ALWAYS be called before Broadcast.
 	// blockNumber: The target block you want to land in.
 	// stateBlock: The block state to simulate on (usually target - 1).
+	// With WithSimulator the bundle runs on that simulator instead, for example a LocalSimulator.
+	// Revert data is decoded with the ABI registry. A failed simulation is returned together with its error:
+	// a *SimulationRevertError when a transaction reverted without being allowed to, a
+	// *SimulationInvalidError when one could not be executed.
+	// WithSimOptions overrides the simulated block (parent, number, coinbase, timestamp, gas limit, base fee).
+	// With WithSimulationCache identical simulations are served from the cache until the next head.
+	// Diagnose classifies a failed simulation and names the offending transaction.
 	Simulate(ctx context.Context, bundle *Bundle, targetBlock uint64, opts ...BundleOption) (*SimulateResponse, error)
 
+	// SimulateMany simulates candidate bundles for targetBlock on a bounded worker pool and returns their
+	// results ranked by score (profit by default), successful simulations first. Work still pending close to
+	// the target block's timestamp is dropped with ErrSimulationDeadline.
+	SimulateMany(ctx context.Context, candidates []*Bundle, targetBlock uint64, opts ...SimulateManyOption) ([]*CandidateResult, error)
+
+	// OptimizeBundle searches the orderings of the bundle's transactions allowed by the constraints (same-sender
+	// nonce order always applies), simulating them to maximize profit or minimize reverts. Small searches are
+	// exhaustive, larger ones a hill climb within the simulation budget. The best bundle comes with the trace.
+	OptimizeBundle(ctx context.Context, bundle *Bundle, targetBlock uint64, opts ...OptimizeOption) (*OptimizeResult, error)
+
+	// Backtest replays the bundle in every block from fromBlock to toBlock on the state that block was built on,
+	// with the local simulator (needs an archive node) or the relay's eth_callBundle, and reports per block
+	// whether it succeeded, its gas and its profit.
+	Backtest(ctx context.Context, bundle *Bundle, fromBlock, toBlock uint64, opts ...BacktestOption) (*BacktestReport, error)
+
 	// Broadcast sends the bundle to the configured list of builders (Titan, Beaver, Flashbots, etc.).
 	// It returns the list of builders that accepted the request.
+	// The bundle is simulated first, with the same options, and checked against the client's pre-flight
+	// policies (WithPreflight); a rejected bundle is not sent and a *PreflightError is returned.
+	// WithSkipPreflight sends it without simulating.
 	Broadcast(ctx context.Context, bundle *Bundle, targetBlock uint64, opts ...BundleOption) (*BroadcastResponse, error)
 
 	// SendPrivateTransaction sends a single transaction directly to builders (eth_sendPrivateTransaction).
@@ -24,16 +52,83 @@ type IFlashbot interface {
 	// expDurationBlocks: The expected duration of the transaction in blocks. max 25 blocks. default 25 blocks.
 	SendPrivateTransaction(ctx context.Context, signedTxHex string, expDurationBlocks uint64) error
 
+	// WaitForInclusion watches the chain for the bundle's transactions until they land or the bundle can no longer land.
+	// maxBlock: The last block the bundle is valid for. 0 means the current head plus the default Broadcast expiration.
+	// Requires an Ethereum client (WithEthClient).
+	WaitForInclusion(ctx context.Context, bundle *Bundle, maxBlock uint64) (*InclusionResult, error)
+
+	// NewConfirmationTracker creates a tracker that follows landed bundles until they have depth confirmations
+	// and reports reorgs that drop them from the canonical chain. depth 0 means the default depth (12).
+	// Requires an Ethereum client (WithEthClient).
+	NewConfirmationTracker(depth uint64) (*ConfirmationTracker, error)
+
+	// NewMevShareStream creates a client of the MEV-Share server-sent event stream at url (MainnetMevShareStreamURL
+	// when empty), which decodes pending transaction and bundle hints to find backrun opportunities.
+	// Run connects and reconnects with backoff; hints are delivered on Events or to WithHintHandler.
+	NewMevShareStream(url string, opts ...MevShareStreamOption) (*MevShareStream, error)
+
+	// ABIRegistry returns the registry used to decode simulation logs into named events with typed arguments.
+	// It is seeded with the ERC-20, ERC-721, ERC-1155 and WETH events (WithABIRegistry replaces it).
+	ABIRegistry() *ABIRegistry
+
+	// NonceManager returns the nonce manager the client reserves nonces with, so transactions signed outside the
+	// client can reserve from the same pool. It is nil unless WithEthClient or WithNonceManager is set.
+	NonceManager() *NonceManager
+
+	// NewTxBuilder returns a builder that turns transaction intents into signed transactions ready for a bundle,
+	// filling in the chain ID, a reserved nonce, the estimated gas limit and strategy-priced fees.
+	// Requires an Ethereum client (WithEthClient).
+	NewTxBuilder(opts ...TxBuilderOption) (*TxBuilder, error)
+
+	// SponsorBundle builds a bundle where sponsorSigner sends each underfunded user exactly the ETH their
+	// transactions can cost (gas limit * fee cap + value) minus their balance. The funding transfers come first.
+	// Requires an Ethereum client (WithEthClient).
+	SponsorBundle(ctx context.Context, userTxs []*types.Transaction, sponsorSigner TxSigner) (*Bundle, error)
+
+	// AddBuilderPayment appends a transaction paying the block builder directly, either a fixed amount
+	// or a share of the simulated profit, and reports the bundle's resulting effective gas price.
+	// Requires an Ethereum client (WithEthClient).
+	AddBuilderPayment(ctx context.Context, bundle *Bundle, payment BuilderPayment) (*BuilderPaymentResult, error)
+
+	// SignAuthorization signs an EIP-7702 authorization delegating the signer's account to delegate at a nonce
+	// reserved from the client's nonce manager. sendsTx must be true when the signer also sends the set-code
+	// transaction carrying the authorization, whose nonce auth.Nonce-1 is reserved too.
+	// Requires an Ethereum client (WithEthClient).
+	SignAuthorization(ctx context.Context, signer AuthorizationSigner, delegate common.Address, sendsTx bool) (types.SetCodeAuthorization, error)
+
+	// ValidateAuthorizations checks the signature, chain ID and nonce of every set-code authorization in the bundle,
+	// replaying the nonces consumed by earlier transactions and authorizations. Invalid ones are *AuthorizationError.
+	// Requires an Ethereum client (WithEthClient).
+	ValidateAuthorizations(ctx context.Context, bundle *Bundle) error
+
 	// --- Gas & Network Intelligence ---
 
 	// GetGasPrice returns the suggested gas price.
 	// For EIP-1559 chains, this should return the BaseFee + PriorityFee.
 	// You can implement this to return a "fast" price for aggressive inclusion.
-	GetGasPrice(ctx context.Context) (gasPrice *big.Int, tip *big.Int, err error)
+	// strategy: (Optional) Overrides the client's gas strategy (WithGasStrategy) for this call.
+	GetGasPrice(ctx context.Context, strategy ...GasStrategy) (gasPrice *big.Int, tip *big.Int, err error)
+
+	// PredictBaseFee projects the expected, worst-case and best-case base fee of a future block
+	// from the latest header using the EIP-1559 update rule.
+	PredictBaseFee(ctx context.Context, targetBlock uint64) (*BaseFeePrediction, error)
+
+	// RecommendFeeCap returns a GasFeeCap that covers the worst-case base fee until maxBlock plus tip.
+	// maxBlock 0 matches Broadcast's default expiration; a nil tip uses the suggested tip.
+	RecommendFeeCap(ctx context.Context, targetBlock uint64, maxBlock uint64, tip *big.Int) (*FeeCapRecommendation, error)
+
+	// PredictBlobBaseFee projects the expected and worst-case blob base fee (EIP-4844) of a future block.
+	PredictBlobBaseFee(ctx context.Context, targetBlock uint64) (*BlobBaseFeePrediction, error)
 
 	// EstimateGasBundle calculates the gas units required for the entire bundle.
 	// This is useful for calculating exactly how much "sponsorship" ETH to send the user.
-	EstimateGasBundle(ctx context.Context, bundle *Bundle) (uint64, error)
+	// By default it sums the signed gas limits; use WithEstimateMode to simulate the bundle instead.
+	EstimateGasBundle(ctx context.Context, bundle *Bundle, opts ...EstimateOption) (*GasEstimate, error)
+
+	// EstimateBundleCost prices the bundle in wei at the projected base fee of targetBlock, per transaction,
+	// per sender and in total, as a min/expected/max range. opts select how gas usage is estimated.
+	// Requires an Ethereum client (WithEthClient).
+	EstimateBundleCost(ctx context.Context, bundle *Bundle, targetBlock uint64, opts ...EstimateOption) (*BundleCost, error)
 
 	// --- Utilities ---
 
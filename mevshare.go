@@ -0,0 +1,272 @@
+package flashbot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// HintKind tells whether a MEV-Share hint is about a pending transaction or a pending bundle.
+type HintKind string
+
+const (
+	HintKindTransaction HintKind = "transaction"
+	HintKindBundle      HintKind = "bundle"
+)
+
+// MevShareHint is an event of the MEV-Share stream: what the sender of a pending transaction or bundle
+// chose to share about it. Fields the sender did not share are empty.
+type MevShareHint struct {
+	Kind HintKind `json:"-"`
+	// ID is the server-sent event id, used to resume the stream after a reconnect.
+	ID string `json:"-"`
+	// Hash is the hash of the transaction or of the bundle, to reference it in a backrun (mev_sendBundle).
+	Hash common.Hash `json:"hash"`
+	Logs []HintLog   `json:"logs"`
+	Txs  []HintTx    `json:"txs"`
+	// MevGasPrice and GasUsed are only shared by some order flow.
+	MevGasPrice *hexutil.Big    `json:"mevGasPrice,omitempty"`
+	GasUsed     *hexutil.Uint64 `json:"gasUsed,omitempty"`
+}
+
+// HintLog is a log emitted by a pending transaction or bundle.
+type HintLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// HintTx is a transaction of a hint.
+type HintTx struct {
+	To               *common.Address `json:"to,omitempty"`
+	FunctionSelector hexutil.Bytes   `json:"functionSelector,omitempty"`
+	CallData         hexutil.Bytes   `json:"callData,omitempty"`
+}
+
+// mevShareStreamConfig holds the settings of a MevShareStream.
+type mevShareStreamConfig struct {
+	handler    func(*MevShareHint)
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// MevShareStreamOption configures a MevShareStream.
+type MevShareStreamOption func(*mevShareStreamConfig) error
+
+// WithHintHandler delivers hints to handler, called from Run, instead of the Events channel.
+func WithHintHandler(handler func(*MevShareHint)) MevShareStreamOption {
+	return func(cfg *mevShareStreamConfig) error {
+		if handler == nil {
+			return fmt.Errorf("handler cannot be nil")
+		}
+		cfg.handler = handler
+		return nil
+	}
+}
+
+// WithHintStreamBackoff sets the delay before reconnecting after the stream drops, doubled after every failed
+// attempt up to max, and reset once events flow again. Defaults to 500ms and 30s.
+func WithHintStreamBackoff(first, max time.Duration) MevShareStreamOption {
+	return func(cfg *mevShareStreamConfig) error {
+		if first <= 0 || max < first {
+			return fmt.Errorf("invalid backoff %s to %s", first, max)
+		}
+		cfg.minBackoff, cfg.maxBackoff = first, max
+		return nil
+	}
+}
+
+// MevShareStream consumes the MEV-Share server-sent event stream of pending transaction and bundle hints,
+// reconnecting when it drops.
+type MevShareStream struct {
+	f       *flashbot
+	url     string
+	cfg     mevShareStreamConfig
+	client  *http.Client
+	events  chan *MevShareHint
+	lastID  string
+	runOnce sync.Once
+}
+
+// ErrStreamStarted is returned by MevShareStream.Run when the stream already ran.
+var ErrStreamStarted = errors.New("mev-share stream already started")
+
+// NewMevShareStream creates a stream of the hints served at url. An empty url means MainnetMevShareStreamURL.
+// The stream connects with a copy of the client's HTTP client without its Timeout, which would cut the
+// long-lived connection.
+func (f *flashbot) NewMevShareStream(url string, opts ...MevShareStreamOption) (*MevShareStream, error) {
+	if url == "" {
+		url = MainnetMevShareStreamURL
+	}
+	cfg := mevShareStreamConfig{minBackoff: defaultHintStreamMinBackoff, maxBackoff: defaultHintStreamMaxBackoff}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+	client := *f.client
+	client.Timeout = 0
+	return &MevShareStream{
+		f:      f,
+		url:    url,
+		cfg:    cfg,
+		client: &client,
+		events: make(chan *MevShareHint, hintEventBuffer),
+	}, nil
+}
+
+// Events returns the channel on which hints are delivered, unless a handler was set with WithHintHandler.
+// The channel is closed when Run returns.
+func (s *MevShareStream) Events() <-chan *MevShareHint {
+	return s.events
+}
+
+// Run connects to the stream and delivers hints until ctx is done, reconnecting with backoff whenever the
+// connection fails or drops. A stream runs once: Run returns ErrStreamStarted when called again.
+func (s *MevShareStream) Run(ctx context.Context) error {
+	started := true
+	s.runOnce.Do(func() { started = false })
+	if started {
+		return ErrStreamStarted
+	}
+	defer close(s.events)
+	backoff := s.cfg.minBackoff
+	for {
+		received, err := s.consume(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			backoff = s.cfg.minBackoff
+		}
+		if err != nil {
+			s.f.logger.WithError(err).WithField("retry_in", backoff).Warn("mev-share stream disconnected")
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if !received {
+			backoff = min(2*backoff, s.cfg.maxBackoff)
+		}
+	}
+}
+
+// consume reads one connection until it ends, and reports whether it delivered any hint.
+func (s *MevShareStream) consume(ctx context.Context) (bool, error) {
+	ctx, span := s.f.tracer.Start(ctx, "flashbot.MevShareStream.consume")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if s.lastID != "" {
+		req.Header.Set("Last-Event-ID", s.lastID)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		span.SetStatus(codes.Error, resp.Status)
+		return false, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	received := false
+	var id string
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxHintEventSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event.
+			if len(data) > 0 {
+				if id != "" {
+					s.lastID = id
+				}
+				if s.dispatch(ctx, id, strings.Join(data, "\n")) {
+					received = true
+				}
+			}
+			id, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment, used as keep-alive
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "id":
+			id = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return received, fmt.Errorf("failed to read stream: %w", err)
+	}
+	span.SetStatus(codes.Error, "stream closed")
+	return received, io.ErrUnexpectedEOF
+}
+
+// dispatch decodes an event and delivers it, and reports whether it was a hint.
+func (s *MevShareStream) dispatch(ctx context.Context, id, data string) bool {
+	hint, err := decodeMevShareHint([]byte(data))
+	if err != nil {
+		s.f.logger.WithError(err).Warn("failed to decode mev-share hint")
+		return false
+	}
+	hint.ID = id
+	if s.cfg.handler != nil {
+		s.cfg.handler(hint)
+		return true
+	}
+	select {
+	case s.events <- hint:
+	case <-ctx.Done():
+	}
+	return true
+}
+
+// decodeMevShareHint decodes the data of a stream event. An event with more than one transaction is a bundle.
+func decodeMevShareHint(data []byte) (*MevShareHint, error) {
+	var hint MevShareHint
+	if err := json.Unmarshal(data, &hint); err != nil {
+		return nil, err
+	}
+	if hint.Hash == (common.Hash{}) {
+		return nil, fmt.Errorf("hint has no hash")
+	}
+	hint.Kind = HintKindTransaction
+	if len(hint.Txs) > 1 {
+		hint.Kind = HintKindBundle
+	}
+	return &hint, nil
+}
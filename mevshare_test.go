@@ -0,0 +1,145 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTxHint     = `{"hash":"0x1111111111111111111111111111111111111111111111111111111111111111","logs":[{"address":"0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2","topics":["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"],"data":"0x01"}],"txs":[{"to":"0x7a250d5630b4cf539739df2c5dacb4c659f2488d","functionSelector":"0x38ed1739","callData":"0x38ed1739ff"}]}`
+	testBundleHint = `{"hash":"0x2222222222222222222222222222222222222222222222222222222222222222","logs":null,
+data: "txs":[{"to":"0x7a250d5630b4cf539739df2c5dacb4c659f2488d"},{"functionSelector":"0xa9059cbb"}],"mevGasPrice":"0x3b9aca00","gasUsed":"0x5208"}`
+)
+
+func TestMevShareStream(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The handler runs on the server's goroutine, where only assert may report failures.
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch connections.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			fmt.Fprintf(w, ": ping\n\nid: 1\ndata: %s\n\ndata: not json\n\n", testTxHint)
+		default:
+			assert.Equal(t, "1", r.Header.Get("Last-Event-ID"))
+			fmt.Fprintf(w, "id: 2\ndata: %s\n\n", testBundleHint)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	fb, err := New(ctx)
+	require.NoError(t, err)
+	stream, err := fb.NewMevShareStream(server.URL, WithHintStreamBackoff(time.Millisecond, 5*time.Millisecond))
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- stream.Run(ctx) }()
+
+	hint := <-stream.Events()
+	require.Equal(t, HintKindTransaction, hint.Kind)
+	require.Equal(t, "1", hint.ID)
+	require.Equal(t, common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111"), hint.Hash)
+	require.Len(t, hint.Logs, 1)
+	require.Equal(t, common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"), hint.Logs[0].Address)
+	require.Len(t, hint.Txs, 1)
+	require.Equal(t, common.HexToAddress("0x7a250d5630b4cf539739df2c5dacb4c659f2488d"), *hint.Txs[0].To)
+	require.Equal(t, []byte{0x38, 0xed, 0x17, 0x39}, []byte(hint.Txs[0].FunctionSelector))
+	require.Equal(t, []byte{0x38, 0xed, 0x17, 0x39, 0xff}, []byte(hint.Txs[0].CallData))
+
+	hint = <-stream.Events()
+	require.Equal(t, HintKindBundle, hint.Kind)
+	require.Len(t, hint.Txs, 2)
+	require.Nil(t, hint.Txs[1].To)
+	require.Equal(t, int64(1e9), hint.MevGasPrice.ToInt().Int64())
+	require.Equal(t, uint64(21000), uint64(*hint.GasUsed))
+
+	require.ErrorIs(t, stream.Run(ctx), ErrStreamStarted)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	_, open := <-stream.Events()
+	require.False(t, open)
+	require.Equal(t, int32(3), connections.Load())
+}
+
+func TestMevShareStreamHandler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "data: %s\n\n", testTxHint)
+	}))
+	defer server.Close()
+
+	fb, err := New(ctx)
+	require.NoError(t, err)
+	received := make(chan *MevShareHint)
+	stream, err := fb.NewMevShareStream(server.URL,
+		WithHintStreamBackoff(time.Millisecond, time.Millisecond),
+		WithHintHandler(func(h *MevShareHint) {
+			select {
+			case received <- h:
+			case <-ctx.Done():
+			}
+		}),
+	)
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- stream.Run(ctx) }()
+
+	// The server closes every connection after one hint, so the second comes from a reconnect.
+	for range 2 {
+		hint := <-received
+		require.Equal(t, HintKindTransaction, hint.Kind)
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	_, err = fb.NewMevShareStream("", WithHintStreamBackoff(time.Second, time.Millisecond))
+	require.Error(t, err)
+	_, err = fb.NewMevShareStream("", WithHintHandler(nil))
+	require.Error(t, err)
+}
+
+func TestMevShareStreamClientTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(200 * time.Millisecond):
+			fmt.Fprintf(w, "data: %s\n\n", testTxHint)
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	fb, err := New(ctx)
+	require.NoError(t, err)
+	// A timeout meant for relay requests must not cut the stream.
+	fb.(*flashbot).client = &http.Client{Timeout: 50 * time.Millisecond}
+	stream, err := fb.NewMevShareStream(server.URL, WithHintStreamBackoff(time.Second, time.Second))
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- stream.Run(ctx) }()
+
+	hint, ok := <-stream.Events()
+	require.True(t, ok)
+	require.Equal(t, HintKindTransaction, hint.Kind)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,275 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceSource reads account nonces from the chain. *ethclient.Client implements it.
+type NonceSource interface {
+	// NonceAt returns the nonce of the account at the latest block when blockNumber is nil.
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	// PendingNonceAt returns the nonce of the account including the node's pending transactions.
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out nonces per sender so bundles in flight at the same time never collide.
+// A sender is synced from the chain on its first reservation. Reserved nonces stay taken until they are
+// released (the bundle expired or was cancelled) or the chain moves past them. Released nonces below the
+// highest reservation leave a gap, which the next reservations fill first.
+// A NonceManager is safe for concurrent use.
+type NonceManager struct {
+	source     NonceSource
+	stuckAfter time.Duration
+
+	mu      sync.Mutex
+	senders map[common.Address]*senderNonces
+}
+
+// senderNonces is the reservation state of a single sender.
+type senderNonces struct {
+	// next is the nonce after the highest reservation, or the pending chain nonce when nothing is reserved.
+	next uint64
+	// reserved maps each reserved nonce to the time it was reserved.
+	reserved map[uint64]time.Time
+	// released holds nonces below next that were given back and must be reused before next.
+	released map[uint64]struct{}
+}
+
+// NonceReport describes the nonce state of a sender, as seen by the manager and the chain.
+type NonceReport struct {
+	Sender common.Address
+	// Confirmed is the nonce of the latest block, Pending the one including the node's mempool.
+	Confirmed uint64
+	Pending   uint64
+	// Next is the nonce the manager hands out after the gaps are filled.
+	Next uint64
+	// Reserved lists the outstanding reservations, in ascending order.
+	Reserved []uint64
+	// Gaps lists released nonces below Next. Reservations above a gap cannot be mined until it is filled.
+	Gaps []uint64
+	// Stuck is true when the Confirmed nonce has been reserved for longer than the manager's stuck threshold,
+	// meaning the transaction using it does not land.
+	Stuck bool
+}
+
+// NewNonceManager returns a nonce manager reading nonces from source.
+// stuckAfter is how long the lowest unconfirmed reservation may stay pending before it is reported stuck,
+// 0 means the default (2 minutes).
+func NewNonceManager(source NonceSource, stuckAfter time.Duration) *NonceManager {
+	if stuckAfter <= 0 {
+		stuckAfter = defaultNonceStuckAfter
+	}
+	return &NonceManager{
+		source:     source,
+		stuckAfter: stuckAfter,
+		senders:    make(map[common.Address]*senderNonces),
+	}
+}
+
+// Reserve reserves n consecutive nonces for sender and returns the first one.
+// A gap of at least n released nonces is reused from its lowest nonce, otherwise nonces are taken after
+// the highest reservation.
+func (m *NonceManager) Reserve(ctx context.Context, sender common.Address, n int) (uint64, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("number of nonces must be positive")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.sender(ctx, sender)
+	if err != nil {
+		return 0, err
+	}
+	first := s.next
+	for _, nonce := range sortedNonces(s.released) {
+		if s.releasedRun(nonce, n) {
+			first = nonce
+			break
+		}
+	}
+	now := time.Now()
+	for nonce := first; nonce < first+uint64(n); nonce++ {
+		delete(s.released, nonce)
+		s.reserved[nonce] = now
+	}
+	if first+uint64(n) > s.next {
+		s.next = first + uint64(n)
+	}
+	return first, nil
+}
+
+// Release gives reserved nonces of sender back, for example after the bundle using them expired or was cancelled.
+// Nonces that are not reserved are ignored.
+func (m *NonceManager) Release(sender common.Address, nonces ...uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.senders[sender]
+	if !ok {
+		return
+	}
+	for _, nonce := range nonces {
+		if _, ok := s.reserved[nonce]; !ok {
+			continue
+		}
+		delete(s.reserved, nonce)
+		s.released[nonce] = struct{}{}
+	}
+	// Released nonces at the top are not gaps, hand them out again from next.
+	for s.next > 0 {
+		if _, ok := s.released[s.next-1]; !ok {
+			break
+		}
+		delete(s.released, s.next-1)
+		s.next--
+	}
+}
+
+// Resync reloads sender from the chain. Reservations and gaps the chain moved past are dropped, and next
+// never falls behind the pending nonce, so nonces consumed outside the manager are not handed out again.
+func (m *NonceManager) Resync(ctx context.Context, sender common.Address) error {
+	confirmed, pending, err := m.chainNonces(ctx, sender)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.senders[sender]
+	if !ok {
+		s = newSenderNonces()
+		m.senders[sender] = s
+	}
+	s.sync(confirmed, pending)
+	return nil
+}
+
+// Report returns the nonce state of sender, resyncing it from the chain first.
+func (m *NonceManager) Report(ctx context.Context, sender common.Address) (*NonceReport, error) {
+	confirmed, pending, err := m.chainNonces(ctx, sender)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := &NonceReport{
+		Sender:    sender,
+		Confirmed: confirmed,
+		Pending:   pending,
+		Next:      pending,
+	}
+	s, ok := m.senders[sender]
+	if !ok {
+		return report, nil
+	}
+	s.sync(confirmed, pending)
+	report.Next = s.next
+	report.Reserved = sortedNonces(s.reserved)
+	report.Gaps = sortedNonces(s.released)
+	if reservedAt, ok := s.reserved[confirmed]; ok && time.Since(reservedAt) > m.stuckAfter {
+		report.Stuck = true
+	}
+	return report, nil
+}
+
+// Senders returns the senders the manager holds state for.
+func (m *NonceManager) Senders() []common.Address {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	senders := make([]common.Address, 0, len(m.senders))
+	for sender := range m.senders {
+		senders = append(senders, sender)
+	}
+	return senders
+}
+
+// sender returns the state of sender, syncing it from the chain the first time. m.mu must be held.
+func (m *NonceManager) sender(ctx context.Context, sender common.Address) (*senderNonces, error) {
+	if s, ok := m.senders[sender]; ok {
+		return s, nil
+	}
+	pending, err := m.source.PendingNonceAt(ctx, sender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending nonce of %s: %w", sender.Hex(), err)
+	}
+	s := newSenderNonces()
+	s.next = pending
+	m.senders[sender] = s
+	return s, nil
+}
+
+func (m *NonceManager) chainNonces(ctx context.Context, sender common.Address) (confirmed, pending uint64, err error) {
+	confirmed, err = m.source.NonceAt(ctx, sender, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get nonce of %s: %w", sender.Hex(), err)
+	}
+	pending, err = m.source.PendingNonceAt(ctx, sender)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get pending nonce of %s: %w", sender.Hex(), err)
+	}
+	return confirmed, pending, nil
+}
+
+func newSenderNonces() *senderNonces {
+	return &senderNonces{
+		reserved: make(map[uint64]time.Time),
+		released: make(map[uint64]struct{}),
+	}
+}
+
+// sync drops state below the confirmed nonce and moves next up to the pending nonce.
+func (s *senderNonces) sync(confirmed, pending uint64) {
+	for nonce := range s.reserved {
+		if nonce < confirmed {
+			delete(s.reserved, nonce)
+		}
+	}
+	for nonce := range s.released {
+		// Pending nonces were consumed by transactions sent outside the manager.
+		if nonce < pending {
+			delete(s.released, nonce)
+		}
+	}
+	if len(s.reserved) == 0 && len(s.released) == 0 {
+		s.next = pending
+	} else if s.next < pending {
+		s.next = pending
+	}
+}
+
+// releasedRun reports whether the n nonces starting at first are all released.
+func (s *senderNonces) releasedRun(first uint64, n int) bool {
+	for nonce := first; nonce < first+uint64(n); nonce++ {
+		if _, ok := s.released[nonce]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// reservedRange returns the n nonces starting at first.
+func reservedRange(first uint64, n int) []uint64 {
+	nonces := make([]uint64, n)
+	for i := range nonces {
+		nonces[i] = first + uint64(i)
+	}
+	return nonces
+}
+
+func sortedNonces[V any](set map[uint64]V) []uint64 {
+	nonces := make([]uint64, 0, len(set))
+	for nonce := range set {
+		nonces = append(nonces, nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	return nonces
+}
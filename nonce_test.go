@@ -0,0 +1,78 @@
+package flashbot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	m := NewNonceManager(ethC, time.Millisecond)
+	sender := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	node.nonces[sender] = 5
+
+	first, err := m.Reserve(ctx, sender, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), first)
+	second, err := m.Reserve(ctx, sender, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), second)
+
+	// Releasing the top reservation hands it out again, releasing a lower one leaves a gap that is filled first.
+	m.Release(sender, 7)
+	third, err := m.Reserve(ctx, sender, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), third)
+	m.Release(sender, 5, 6)
+
+	report, err := m.Report(ctx, sender)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{7}, report.Reserved)
+	require.Equal(t, []uint64{5, 6}, report.Gaps)
+	require.Equal(t, uint64(8), report.Next)
+	require.False(t, report.Stuck)
+
+	refill, err := m.Reserve(ctx, sender, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), refill)
+
+	time.Sleep(5 * time.Millisecond)
+	report, err = m.Report(ctx, sender)
+	require.NoError(t, err)
+	require.Empty(t, report.Gaps)
+	require.True(t, report.Stuck)
+
+	// The chain moved past the reservations, possibly through transactions sent elsewhere.
+	node.nonces[sender] = 10
+	require.NoError(t, m.Resync(ctx, sender))
+	next, err := m.Reserve(ctx, sender, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), next)
+}
+
+func TestWaitForInclusionReleasesExpiredNonces(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC), WithPollInterval(time.Millisecond))
+	require.NoError(t, err)
+
+	tx, sender := newTestTx(t, 0)
+	nonce, err := fb.NonceManager().Reserve(ctx, sender, 1)
+	require.NoError(t, err)
+	require.Equal(t, tx.Nonce(), nonce)
+
+	node.setHead(100)
+	res, err := fb.WaitForInclusion(ctx, &Bundle{Transactions: []*types.Transaction{tx}}, 100)
+	require.NoError(t, err)
+	require.Equal(t, InclusionStatusExpired, res.Status)
+
+	nonce, err = fb.NonceManager().Reserve(ctx, sender, 1)
+	require.NoError(t, err)
+	require.Equal(t, tx.Nonce(), nonce)
+}
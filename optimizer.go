@@ -0,0 +1,476 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// OrderConstraint requires the transaction at index Before to come before the one at index After.
+// Indices refer to the bundle given to OptimizeBundle.
+type OrderConstraint struct {
+	Before int
+	After  int
+}
+
+// OrderObjective is what OptimizeBundle maximizes.
+type OrderObjective string
+
+const (
+	// OrderObjectiveProfit picks the successful ordering paying the builder the most, fewer reverts break ties.
+	OrderObjectiveProfit OrderObjective = "profit"
+	// OrderObjectiveReverts picks the ordering with the fewest reverted transactions, higher profit breaks ties.
+	// The relay does not report which transactions reverted, so against it a failed ordering counts as one.
+	OrderObjectiveReverts OrderObjective = "reverts"
+)
+
+// OrderTrial is one simulated ordering.
+type OrderTrial struct {
+	// Order lists the indices of the original transactions in the order they were simulated.
+	Order    []int
+	Response *SimulateResponse
+	// Profit is zero and Reverts is -1 when the ordering was not simulated.
+	Profit  *big.Int
+	Reverts int
+	Err     error
+}
+
+// OptimizeResult is the outcome of OptimizeBundle.
+type OptimizeResult struct {
+	// Bundle is the original bundle reordered as Best.Order, nil when no ordering qualified.
+	Bundle *Bundle
+	Best   *OrderTrial
+	// Exhaustive is true when every valid ordering was simulated.
+	Exhaustive bool
+	// Trace lists every simulated ordering, in the order they were tried.
+	Trace []*OrderTrial
+}
+
+// optimizeConfig holds the settings of a single OptimizeBundle call.
+type optimizeConfig struct {
+	constraints []OrderConstraint
+	objective   OrderObjective
+	budget      int
+	simOpts     []SimulateManyOption
+}
+
+// OptimizeOption configures OptimizeBundle.
+type OptimizeOption func(*optimizeConfig) error
+
+// WithOrderConstraints adds ordering constraints, such as those of FundingConstraints.
+// Transactions of the same sender always keep their nonce order.
+func WithOrderConstraints(constraints ...OrderConstraint) OptimizeOption {
+	return func(cfg *optimizeConfig) error {
+		cfg.constraints = append(cfg.constraints, constraints...)
+		return nil
+	}
+}
+
+// WithOrderObjective sets what the optimizer maximizes. Defaults to OrderObjectiveProfit.
+func WithOrderObjective(objective OrderObjective) OptimizeOption {
+	return func(cfg *optimizeConfig) error {
+		switch objective {
+		case OrderObjectiveProfit, OrderObjectiveReverts:
+			cfg.objective = objective
+			return nil
+		default:
+			return fmt.Errorf("unknown order objective %q", objective)
+		}
+	}
+}
+
+// WithOptimizeBudget sets the maximum number of simulations. Bundles with no more valid orderings than the
+// budget are searched exhaustively, others heuristically. Defaults to 120, every ordering of 5 transactions.
+func WithOptimizeBudget(simulations int) OptimizeOption {
+	return func(cfg *optimizeConfig) error {
+		if simulations <= 0 {
+			return fmt.Errorf("simulation budget must be positive")
+		}
+		cfg.budget = simulations
+		return nil
+	}
+}
+
+// WithOptimizeSimulateOptions sets the SimulateMany options orderings are simulated with, such as the
+// number of workers, the rate limit and the deadline. Scorers are ignored.
+func WithOptimizeSimulateOptions(opts ...SimulateManyOption) OptimizeOption {
+	return func(cfg *optimizeConfig) error {
+		cfg.simOpts = opts
+		return nil
+	}
+}
+
+// FundingConstraints returns a constraint putting every transaction that sends ETH to an address before
+// the transactions that address sends, the "funding before spend" rule of sponsored bundles.
+func FundingConstraints(bundle *Bundle) ([]OrderConstraint, error) {
+	senders, err := bundleSenders(bundle)
+	if err != nil {
+		return nil, err
+	}
+	var constraints []OrderConstraint
+	for i, tx := range bundle.Transactions {
+		if tx.To() == nil || tx.Value().Sign() == 0 {
+			continue
+		}
+		for j, sender := range senders {
+			if j != i && sender == *tx.To() {
+				constraints = append(constraints, OrderConstraint{Before: i, After: j})
+			}
+		}
+	}
+	return constraints, nil
+}
+
+// OptimizeBundle searches the orderings of bundle that satisfy the constraints, simulating them with
+// SimulateMany, and returns the best one with the trace of the search. Transactions keep their CanRevert flag.
+// When the valid orderings fit the budget all of them are simulated; otherwise a hill climb starts from the
+// given order, as far as the constraints allow, and moves to the best ordering one adjacent swap away until
+// none improves or the budget runs out.
+// An error is returned along with the trace when no ordering qualifies.
+func (f *flashbot) OptimizeBundle(ctx context.Context, bundle *Bundle, targetBlock uint64, opts ...OptimizeOption) (*OptimizeResult, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.OptimizeBundle")
+	defer span.End()
+
+	if len(bundle.Transactions) == 0 {
+		span.SetStatus(codes.Error, "bundle is empty")
+		return nil, fmt.Errorf("bundle cannot be empty")
+	}
+	cfg := optimizeConfig{
+		objective: OrderObjectiveProfit,
+		budget:    defaultOptimizeBudget,
+	}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+	graph, err := newOrderGraph(bundle, cfg.constraints)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	search := &orderSearch{f: f, bundle: bundle, targetBlock: targetBlock, cfg: cfg, seen: make(map[string]bool)}
+	orders := graph.orders(cfg.budget + 1)
+	if len(orders) <= cfg.budget {
+		search.result.Exhaustive = true
+		_, err = search.try(ctx, orders)
+	} else {
+		err = search.climb(ctx, graph)
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	res := &search.result
+	if res.Best == nil {
+		span.SetStatus(codes.Error, "no ordering qualified")
+		return res, fmt.Errorf("none of the %d simulated orderings qualified", len(res.Trace))
+	}
+	res.Bundle = bundle.reorder(res.Best.Order)
+	span.SetStatus(codes.Ok, "bundle optimized")
+	return res, nil
+}
+
+// orderSearch holds the state of a single OptimizeBundle call.
+type orderSearch struct {
+	f           *flashbot
+	bundle      *Bundle
+	targetBlock uint64
+	cfg         optimizeConfig
+	// seen holds the key of every simulated ordering.
+	seen   map[string]bool
+	result OptimizeResult
+}
+
+// try simulates the orderings not simulated yet, within the remaining budget, adds them to the trace and
+// returns whether the best trial improved.
+func (s *orderSearch) try(ctx context.Context, orders [][]int) (bool, error) {
+	var fresh [][]int
+	for _, order := range orders {
+		if len(s.result.Trace)+len(fresh) >= s.cfg.budget {
+			break
+		}
+		if key := orderKey(order); !s.seen[key] {
+			s.seen[key] = true
+			fresh = append(fresh, order)
+		}
+	}
+	if len(fresh) == 0 {
+		return false, nil
+	}
+	candidates := make([]*Bundle, len(fresh))
+	for i, order := range fresh {
+		candidates[i] = s.bundle.reorder(order)
+	}
+	results, err := s.f.SimulateMany(ctx, candidates, s.targetBlock, s.cfg.simOpts...)
+	if err != nil {
+		return false, err
+	}
+	trials := make([]*OrderTrial, len(fresh))
+	for _, r := range results {
+		trial := &OrderTrial{Order: fresh[r.Index], Response: r.Response, Profit: new(big.Int), Reverts: -1, Err: r.Err}
+		if r.Response != nil {
+			trial.Reverts = countReverts(r.Response)
+			if profit, err := parseBigInt(r.Response.Profit); err == nil {
+				trial.Profit = profit
+			}
+		}
+		trials[r.Index] = trial
+	}
+
+	improved := false
+	for _, trial := range trials {
+		s.result.Trace = append(s.result.Trace, trial)
+		if s.qualifies(trial) && (s.result.Best == nil || s.better(trial, s.result.Best)) {
+			s.result.Best = trial
+			improved = true
+		}
+	}
+	return improved, nil
+}
+
+// climb runs the hill climb from the first valid ordering.
+func (s *orderSearch) climb(ctx context.Context, graph *orderGraph) error {
+	if _, err := s.try(ctx, [][]int{graph.first()}); err != nil {
+		return err
+	}
+	current := s.result.Trace[0].Order
+	for len(s.result.Trace) < s.cfg.budget && ctx.Err() == nil {
+		var neighbours [][]int
+		for i := 0; i+1 < len(current); i++ {
+			if !graph.mustPrecede(current[i], current[i+1]) {
+				swapped := append([]int(nil), current...)
+				swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+				neighbours = append(neighbours, swapped)
+			}
+		}
+		improved, err := s.try(ctx, neighbours)
+		if err != nil {
+			return err
+		}
+		if !improved {
+			return nil
+		}
+		current = s.result.Best.Order
+	}
+	return nil
+}
+
+// qualifies reports whether trial can be the result of the search.
+func (s *orderSearch) qualifies(trial *OrderTrial) bool {
+	if s.cfg.objective == OrderObjectiveReverts {
+		return trial.Response != nil
+	}
+	return trial.Err == nil && trial.Response.Success
+}
+
+// better reports whether a beats b for the objective.
+func (s *orderSearch) better(a, b *OrderTrial) bool {
+	if s.cfg.objective == OrderObjectiveReverts {
+		if a.Reverts != b.Reverts {
+			return a.Reverts < b.Reverts
+		}
+		if a.Response.Success != b.Response.Success {
+			return a.Response.Success
+		}
+		return a.Profit.Cmp(b.Profit) > 0
+	}
+	if c := a.Profit.Cmp(b.Profit); c != 0 {
+		return c > 0
+	}
+	return a.Reverts < b.Reverts
+}
+
+// orderGraph holds the ordering constraints of a bundle as a precedence graph.
+type orderGraph struct {
+	n int
+	// after maps each transaction to those that must come after it.
+	after [][]int
+	// precedes[i][j] is true when a constraint puts i directly before j.
+	precedes [][]bool
+}
+
+// newOrderGraph builds the graph of constraints plus the nonce order of each sender, and rejects cycles.
+func newOrderGraph(bundle *Bundle, constraints []OrderConstraint) (*orderGraph, error) {
+	n := len(bundle.Transactions)
+	g := &orderGraph{n: n, after: make([][]int, n), precedes: make([][]bool, n)}
+	for i := range g.precedes {
+		g.precedes[i] = make([]bool, n)
+	}
+	senders, err := bundleSenders(bundle)
+	if err != nil {
+		return nil, err
+	}
+	// Same-sender transactions must keep their nonce order, whatever their order in the bundle.
+	last := make(map[common.Address]int)
+	byNonce := make([]int, n)
+	for i := range byNonce {
+		byNonce[i] = i
+	}
+	sort.SliceStable(byNonce, func(a, b int) bool {
+		return bundle.Transactions[byNonce[a]].Nonce() < bundle.Transactions[byNonce[b]].Nonce()
+	})
+	for _, i := range byNonce {
+		if prev, ok := last[senders[i]]; ok {
+			constraints = append(constraints, OrderConstraint{Before: prev, After: i})
+		}
+		last[senders[i]] = i
+	}
+
+	for _, c := range constraints {
+		if c.Before < 0 || c.Before >= n || c.After < 0 || c.After >= n || c.Before == c.After {
+			return nil, fmt.Errorf("invalid order constraint %d before %d for %d transactions", c.Before, c.After, n)
+		}
+		if !g.precedes[c.Before][c.After] {
+			g.precedes[c.Before][c.After] = true
+			g.after[c.Before] = append(g.after[c.Before], c.After)
+		}
+	}
+	if g.hasCycle() {
+		return nil, fmt.Errorf("order constraints contain a cycle")
+	}
+	return g, nil
+}
+
+// hasCycle reports whether the constraints contradict each other, with Kahn's algorithm: a graph is acyclic
+// when repeatedly removing the transactions nothing must precede removes them all.
+func (g *orderGraph) hasCycle() bool {
+	indegree := make([]int, g.n)
+	for i := range g.after {
+		for _, j := range g.after[i] {
+			indegree[j]++
+		}
+	}
+	ready := make([]int, 0, g.n)
+	for i, d := range indegree {
+		if d == 0 {
+			ready = append(ready, i)
+		}
+	}
+	removed := 0
+	for len(ready) > 0 {
+		i := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		removed++
+		for _, j := range g.after[i] {
+			if indegree[j]--; indegree[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	return removed < g.n
+}
+
+// mustPrecede reports whether a constraint puts i directly before j.
+func (g *orderGraph) mustPrecede(i, j int) bool {
+	return g.precedes[i][j]
+}
+
+// first returns the valid ordering closest to the original one: the lowest available index goes first.
+func (g *orderGraph) first() []int {
+	return g.orders(1)[0]
+}
+
+// orders returns up to limit valid orderings, the first one being the closest to the original order.
+func (g *orderGraph) orders(limit int) [][]int {
+	indegree := make([]int, g.n)
+	for i := range g.after {
+		for _, j := range g.after[i] {
+			indegree[j]++
+		}
+	}
+	var orders [][]int
+	order := make([]int, 0, g.n)
+	used := make([]bool, g.n)
+	var walk func()
+	walk = func() {
+		if len(orders) >= limit {
+			return
+		}
+		if len(order) == g.n {
+			orders = append(orders, append([]int(nil), order...))
+			return
+		}
+		for i := 0; i < g.n; i++ {
+			if used[i] || indegree[i] != 0 {
+				continue
+			}
+			used[i] = true
+			order = append(order, i)
+			for _, j := range g.after[i] {
+				indegree[j]--
+			}
+			walk()
+			for _, j := range g.after[i] {
+				indegree[j]++
+			}
+			order = order[:len(order)-1]
+			used[i] = false
+		}
+	}
+	walk()
+	return orders
+}
+
+// reorder returns a copy of the bundle with its transactions, and their CanRevert flags, in order.
+func (b *Bundle) reorder(order []int) *Bundle {
+	reordered := *b
+	reordered.Transactions = make([]*types.Transaction, len(order))
+	reordered.CanRevert = make([]bool, len(order))
+	for i, j := range order {
+		reordered.Transactions[i] = b.Transactions[j]
+		reordered.CanRevert[i] = b.canRevert(j)
+	}
+	return &reordered
+}
+
+// bundleSenders recovers the sender of every transaction of the bundle.
+func bundleSenders(bundle *Bundle) ([]common.Address, error) {
+	senders := make([]common.Address, len(bundle.Transactions))
+	for i, tx := range bundle.Transactions {
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+		}
+		senders[i] = sender
+	}
+	return senders, nil
+}
+
+// countReverts returns the number of transactions that reverted in the simulation, nested bundles included.
+// The relay reports no per-transaction results, a failed relay simulation counts as one revert.
+func countReverts(res *SimulateResponse) int {
+	reverts := 0
+	for i := range res.Logs {
+		res.Logs[i].walk(func(txResult *TxLogResult) {
+			if txResult.Error != "" || txResult.Revert != "" {
+				reverts++
+			}
+		})
+	}
+	if reverts == 0 && (res.Revert != "" || res.ExecError != "") {
+		reverts = 1
+	}
+	return reverts
+}
+
+func orderKey(order []int) string {
+	parts := make([]string, len(order))
+	for i, j := range order {
+		parts[i] = strconv.Itoa(j)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,147 @@
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptimizeBundle(t *testing.T) {
+	ctx := context.Background()
+	txs := make([]*types.Transaction, 3)
+	ids := make(map[common.Hash]uint64)
+	for i := range txs {
+		txs[i], _ = newTestTx(t, 0)
+		ids[txs[i].Hash()] = uint64(i)
+	}
+	// The profit of an ordering is ten times the id of its first transaction plus the id of its last one.
+	relay := newTestRelay(t)
+	relay.handle(methodMevSimBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		var p mevSimBundleParams
+		if err := json.Unmarshal(params[0], &p); err != nil {
+			return nil, invalidParams(t, err)
+		}
+		order := make([]uint64, len(p.Body))
+		for i, item := range p.Body {
+			var tx types.Transaction
+			if err := tx.UnmarshalBinary(hexutil.MustDecode(*item.Tx)); err != nil {
+				return nil, invalidParams(t, err)
+			}
+			order[i] = ids[tx.Hash()]
+		}
+		return MevSimResponse{Success: true, Profit: hexutil.EncodeUint64(order[0]*10 + order[len(order)-1])}, nil
+	})
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+	bundle := &Bundle{Transactions: txs, CanRevert: []bool{false, true, false}}
+
+	t.Run("exhaustive", func(t *testing.T) {
+		res, err := fb.OptimizeBundle(ctx, bundle, 101, WithOrderConstraints(OrderConstraint{Before: 0, After: 1}))
+		require.NoError(t, err)
+		require.True(t, res.Exhaustive)
+		require.Len(t, res.Trace, 3) // [0 1 2], [0 2 1] and [2 0 1]
+		require.Equal(t, []int{2, 0, 1}, res.Best.Order)
+		require.Equal(t, big.NewInt(21), res.Best.Profit)
+		require.Equal(t, []*types.Transaction{txs[2], txs[0], txs[1]}, res.Bundle.Transactions)
+		require.Equal(t, []bool{false, false, true}, res.Bundle.CanRevert)
+	})
+
+	t.Run("heuristic", func(t *testing.T) {
+		res, err := fb.OptimizeBundle(ctx, bundle, 101, WithOptimizeBudget(3))
+		require.NoError(t, err)
+		require.False(t, res.Exhaustive)
+		// [0 1 2] first, then its neighbours [1 0 2] and [0 2 1].
+		require.Len(t, res.Trace, 3)
+		require.Equal(t, []int{1, 0, 2}, res.Best.Order)
+	})
+
+	t.Run("constraints", func(t *testing.T) {
+		_, err := fb.OptimizeBundle(ctx, bundle, 101, WithOrderConstraints(
+			OrderConstraint{Before: 0, After: 1},
+			OrderConstraint{Before: 1, After: 0},
+		))
+		require.ErrorContains(t, err, "cycle")
+		_, err = fb.OptimizeBundle(ctx, bundle, 101, WithOrderConstraints(OrderConstraint{Before: 0, After: 3}))
+		require.ErrorContains(t, err, "invalid order constraint")
+	})
+}
+
+func TestOrderConstraints(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(SepoliaChainID))
+	sponsor, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	user, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	userAddr := crypto.PubkeyToAddress(user.PublicKey)
+	sign := func(pk *ecdsa.PrivateKey, nonce uint64, to common.Address, value int64) *types.Transaction {
+		tx, err := types.SignNewTx(pk, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(SepoliaChainID),
+			Nonce:     nonce,
+			To:        &to,
+			Value:     big.NewInt(value),
+			Gas:       21000,
+			GasFeeCap: big.NewInt(2e9),
+			GasTipCap: big.NewInt(1e9),
+		})
+		require.NoError(t, err)
+		return tx
+	}
+	// The user's transactions come in reverse nonce order, the funding transaction last.
+	bundle := &Bundle{Transactions: []*types.Transaction{
+		sign(user, 1, common.Address{1}, 0),
+		sign(user, 0, common.Address{1}, 0),
+		sign(sponsor, 0, userAddr, 1e15),
+	}}
+
+	funding, err := FundingConstraints(bundle)
+	require.NoError(t, err)
+	require.Equal(t, []OrderConstraint{{Before: 2, After: 0}, {Before: 2, After: 1}}, funding)
+
+	graph, err := newOrderGraph(bundle, funding)
+	require.NoError(t, err)
+	require.Equal(t, [][]int{{2, 1, 0}}, graph.orders(10))
+}
+
+func TestOrderGraphCycle(t *testing.T) {
+	// A cycle among two transactions of a large bundle is found without enumerating the orderings of the others.
+	txs := make([]*types.Transaction, 20)
+	for i := range txs {
+		txs[i], _ = newTestTx(t, 0)
+	}
+	bundle := &Bundle{Transactions: txs}
+	done := make(chan error, 1)
+	go func() {
+		_, err := newOrderGraph(bundle, []OrderConstraint{{Before: 18, After: 19}, {Before: 19, After: 18}})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.ErrorContains(t, err, "cycle")
+	case <-time.After(5 * time.Second):
+		t.Fatal("cycle detection did not finish")
+	}
+
+	graph, err := newOrderGraph(bundle, []OrderConstraint{{Before: 19, After: 0}})
+	require.NoError(t, err)
+	first := graph.first()
+	require.Equal(t, []int{19, 0}, first[len(first)-2:])
+}
+
+func TestCountReverts(t *testing.T) {
+	require.Equal(t, 0, countReverts(&SimulateResponse{Success: true, Logs: []TxLogResult{{}, {}}}))
+	require.Equal(t, 1, countReverts(&SimulateResponse{Logs: []TxLogResult{{}, {Error: "execution reverted"}}}))
+	// The relay only reports the bundle's failure.
+	require.Equal(t, 1, countReverts(&SimulateResponse{ExecError: "execution reverted", Logs: []TxLogResult{{}, {}}}))
+	// Reverts inside a nested bundle count too.
+	nested := []TxLogResult{{BundleLogs: []TxLogResult{{}, {Error: "execution reverted"}}}, {}}
+	require.Equal(t, 1, countReverts(&SimulateResponse{Logs: nested}))
+}
@@ -1,5 +1,12 @@
 package flashbot
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
 type Option func(*flashbot) error
 
 func WithBuilders(builders []string) Option {
@@ -22,3 +29,93 @@ func WithChainID(chainID uint64) Option {
 		return nil
 	}
 }
+
+// WithEthClient sets the Ethereum node client used for chain queries such as gas prices and inclusion tracking.
+func WithEthClient(ethC *ethclient.Client) Option {
+	return func(f *flashbot) error {
+		f.ethC = ethC
+		return nil
+	}
+}
+
+// WithPollInterval sets how often the client polls the Ethereum node while waiting on chain events.
+func WithPollInterval(interval time.Duration) Option {
+	return func(f *flashbot) error {
+		if interval <= 0 {
+			return fmt.Errorf("poll interval must be positive")
+		}
+		f.pollInterval = interval
+		return nil
+	}
+}
+
+// WithBuilderRegistry sets the builder name to fee recipient mapping used for builder payments.
+func WithBuilderRegistry(registry BuilderRegistry) Option {
+	return func(f *flashbot) error {
+		f.builderRegistry = registry
+		return nil
+	}
+}
+
+// WithSimulationCache makes Simulate reuse results of identical simulations until the next head,
+// and merge concurrent identical calls into one.
+func WithSimulationCache(c *SimulationCache) Option {
+	return func(f *flashbot) error {
+		if c == nil {
+			return fmt.Errorf("simulation cache cannot be nil")
+		}
+		f.simCache = c
+		return nil
+	}
+}
+
+// WithPreflight sets the policies Broadcast checks the bundle's simulation against before sending it,
+// replacing the default (RequireSimulationSuccess). Without policies Broadcast does not simulate.
+func WithPreflight(policies ...PreflightPolicy) Option {
+	return func(f *flashbot) error {
+		for _, policy := range policies {
+			if policy == nil {
+				return fmt.Errorf("pre-flight policy cannot be nil")
+			}
+		}
+		f.preflight = policies
+		return nil
+	}
+}
+
+// WithGasStrategy sets the default strategy GetGasPrice uses to price transactions from eth_feeHistory.
+func WithGasStrategy(strategy GasStrategy) Option {
+	return func(f *flashbot) error {
+		f.gasStrategy = strategy
+		return nil
+	}
+}
+
+// WithNonceManager sets the nonce manager used to reserve nonces for the transactions the client signs.
+// By default a manager reading from the Ethereum client (WithEthClient) is created.
+func WithNonceManager(m *NonceManager) Option {
+	return func(f *flashbot) error {
+		f.nonces = m
+		return nil
+	}
+}
+
+// WithSimulator sets the backend Simulate (and Broadcast's pre-simulation) runs bundles on instead of the relay,
+// for example a LocalSimulator.
+func WithSimulator(sim Simulator) Option {
+	return func(f *flashbot) error {
+		f.simulator = sim
+		return nil
+	}
+}
+
+// WithABIRegistry sets the registry simulation logs are decoded with. Defaults to DefaultABIRegistry.
+func WithABIRegistry(r *ABIRegistry) Option {
+	return func(f *flashbot) error {
+		if r == nil {
+			return fmt.Errorf("ABI registry cannot be nil")
+		}
+		f.abis = r
+		return nil
+	}
+}
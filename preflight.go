@@ -0,0 +1,164 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PreflightPolicy checks the simulation of a bundle before Broadcast sends it.
+// Broadcast simulates the bundle once and runs every policy of the chain on the result, in order,
+// stopping at the first one that fails.
+type PreflightPolicy interface {
+	// Name identifies the policy in errors and traces.
+	Name() string
+	// Check returns an error when the bundle must not be sent.
+	Check(ctx context.Context, bundle *Bundle, res *SimulateResponse) error
+}
+
+// PreflightError is returned by Broadcast when a pre-flight policy rejects the bundle.
+// The bundle was not sent.
+type PreflightError struct {
+	Policy string
+	Err    error
+}
+
+func (e *PreflightError) Error() string {
+	return fmt.Sprintf("pre-flight policy %s rejected the bundle: %v", e.Policy, e.Err)
+}
+
+func (e *PreflightError) Unwrap() error {
+	return e.Err
+}
+
+// preflightFunc adapts a function to a PreflightPolicy.
+type preflightFunc struct {
+	name  string
+	check func(ctx context.Context, bundle *Bundle, res *SimulateResponse) error
+}
+
+var _ PreflightPolicy = (*preflightFunc)(nil)
+
+// PreflightFunc returns a policy running check, for custom rules that do not need their own type.
+func PreflightFunc(name string, check func(ctx context.Context, bundle *Bundle, res *SimulateResponse) error) PreflightPolicy {
+	return &preflightFunc{name: name, check: check}
+}
+
+func (p *preflightFunc) Name() string {
+	return p.name
+}
+
+func (p *preflightFunc) Check(ctx context.Context, bundle *Bundle, res *SimulateResponse) error {
+	return p.check(ctx, bundle, res)
+}
+
+// DefaultPreflight returns the policies Broadcast runs unless WithPreflight is set: the simulation must succeed.
+func DefaultPreflight() []PreflightPolicy {
+	return []PreflightPolicy{RequireSimulationSuccess()}
+}
+
+// RequireSimulationSuccess rejects bundles whose simulation did not succeed.
+func RequireSimulationSuccess() PreflightPolicy {
+	return PreflightFunc("simulation-success", func(_ context.Context, _ *Bundle, res *SimulateResponse) error {
+		return simulationFailure(res)
+	})
+}
+
+// simulationFailure returns why the simulation did not succeed, nil when it did.
+func simulationFailure(res *SimulateResponse) error {
+	switch {
+	case res.Success:
+		return nil
+	case res.RevertReason != nil && res.RevertReason.Kind != RevertKindUnknown:
+		return fmt.Errorf("simulation failed: %s", res.RevertReason)
+	case res.Error != "":
+		return fmt.Errorf("simulation failed: %s", res.Error)
+	case res.ExecError != "":
+		return fmt.Errorf("simulation failed: %s", res.ExecError)
+	default:
+		return fmt.Errorf("simulation failed")
+	}
+}
+
+// RequireMinProfit rejects bundles whose simulated profit, what the builder earns, is below min wei.
+func RequireMinProfit(min *big.Int) PreflightPolicy {
+	return PreflightFunc("min-profit", func(_ context.Context, _ *Bundle, res *SimulateResponse) error {
+		profit, err := parseBigInt(res.Profit)
+		if err != nil {
+			return fmt.Errorf("invalid profit: %w", err)
+		}
+		if profit.Cmp(min) < 0 {
+			return fmt.Errorf("profit %s is below %s", profit, min)
+		}
+		return nil
+	})
+}
+
+// RequireMaxGasUsed rejects bundles using more than max gas in simulation.
+func RequireMaxGasUsed(max uint64) PreflightPolicy {
+	return PreflightFunc("max-gas-used", func(_ context.Context, _ *Bundle, res *SimulateResponse) error {
+		gasUsed, err := parseBigInt(res.GasUsed)
+		if err != nil {
+			return fmt.Errorf("invalid gas used: %w", err)
+		}
+		if !gasUsed.IsUint64() || gasUsed.Uint64() > max {
+			return fmt.Errorf("gas used %s exceeds %d", gasUsed, max)
+		}
+		return nil
+	})
+}
+
+// RequireNoReverts rejects bundles in which a transaction reverted, other than those at the allowed indices.
+// A nested bundle is rejected when any of its transactions reverted.
+// Unlike the bundle's CanRevert flags, which builders honor, it keeps transactions that may revert
+// on chain from reverting in simulation.
+func RequireNoReverts(allowed ...int) PreflightPolicy {
+	allowedSet := make(map[int]struct{}, len(allowed))
+	for _, i := range allowed {
+		allowedSet[i] = struct{}{}
+	}
+	return PreflightFunc("no-reverts", func(_ context.Context, _ *Bundle, res *SimulateResponse) error {
+		for i := range res.Logs {
+			txResult, failed := res.Logs[i].failed()
+			if !failed {
+				continue
+			}
+			if _, ok := allowedSet[i]; ok {
+				continue
+			}
+			reason := txResult.Error
+			if txResult.RevertReason != nil {
+				reason = txResult.RevertReason.Message
+			}
+			return fmt.Errorf("transaction %d reverted: %s", i, reason)
+		}
+		return nil
+	})
+}
+
+// RequireAssetChange rejects bundles after which addr nets less than min of asset, as reported by
+// AnalyzeAssetChanges with opts. A negative min bounds a loss.
+func RequireAssetChange(addr common.Address, asset Asset, min *big.Int, opts ...AssetChangesOption) PreflightPolicy {
+	return PreflightFunc("asset-change", func(_ context.Context, bundle *Bundle, res *SimulateResponse) error {
+		changes, err := AnalyzeAssetChanges(bundle, res, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to analyze asset changes: %w", err)
+		}
+		if delta := changes.Delta(addr, asset); delta.Cmp(min) < 0 {
+			return fmt.Errorf("%s nets %s of %s, below %s", addr.Hex(), delta, asset, min)
+		}
+		return nil
+	})
+}
+
+// runPreflight runs the policies on a simulation and wraps the first failure in a *PreflightError.
+func runPreflight(ctx context.Context, policies []PreflightPolicy, bundle *Bundle, res *SimulateResponse) error {
+	for _, policy := range policies {
+		if err := policy.Check(ctx, bundle, res); err != nil {
+			return &PreflightError{Policy: policy.Name(), Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastPreflight(t *testing.T) {
+	ctx := context.Background()
+	relay := newTestRelay(t)
+	sim := &MevSimResponse{Success: true, Profit: "0x64", GasUsed: "0x5208"}
+	relay.handle(methodMevSimBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		var p mevSimBundleParams
+		if err := json.Unmarshal(params[0], &p); err != nil {
+			return nil, invalidParams(t, err)
+		}
+		assert.NotNil(t, p.Privacy, "broadcast options are simulated too")
+		return sim, nil
+	})
+	relay.handle(methodMevSendBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		return BroadcastResponse{BundleHash: "0x01"}, nil
+	})
+
+	tx, _ := newTestTx(t, 0)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx}}
+	privacy := WithPrivacy(MevSendBundlePrivacy{Hints: []string{"hash"}})
+
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+	res, err := fb.Broadcast(ctx, bundle, 100, privacy)
+	require.NoError(t, err)
+	require.Equal(t, "0x01", res.BundleHash)
+	require.Equal(t, 1, relay.callCount(methodMevSimBundle))
+
+	t.Run("failed simulation", func(t *testing.T) {
+		sim.Success, sim.Error = false, "insufficient funds"
+		defer func() { sim.Success, sim.Error = true, "" }()
+
+		_, err := fb.Broadcast(ctx, bundle, 100, privacy)
+		var preflightErr *PreflightError
+		require.ErrorAs(t, err, &preflightErr)
+		require.Equal(t, "simulation-success", preflightErr.Policy)
+		require.ErrorContains(t, err, "insufficient funds")
+		require.Equal(t, 1, relay.callCount(methodMevSendBundle))
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		sims := relay.callCount(methodMevSimBundle)
+		_, err := fb.Broadcast(ctx, bundle, 100, WithSkipPreflight())
+		require.NoError(t, err)
+		require.Equal(t, sims, relay.callCount(methodMevSimBundle))
+	})
+
+	t.Run("custom policies", func(t *testing.T) {
+		fb, err := New(ctx, WithRelayURL(relay.URL), WithPreflight(
+			RequireSimulationSuccess(),
+			RequireMaxGasUsed(21000),
+			RequireMinProfit(big.NewInt(101)),
+		))
+		require.NoError(t, err)
+		_, err = fb.Broadcast(ctx, bundle, 100, privacy)
+		var preflightErr *PreflightError
+		require.ErrorAs(t, err, &preflightErr)
+		require.Equal(t, "min-profit", preflightErr.Policy)
+	})
+}
+
+func TestPreflightPolicies(t *testing.T) {
+	ctx := context.Background()
+	tx, sender := newTestTx(t, 0) // sends 1 wei
+	bundle := &Bundle{Transactions: []*types.Transaction{tx, tx}}
+	res := &SimulateResponse{
+		Success: true,
+		Profit:  "100",
+		GasUsed: "0xa410",
+		Logs:    []TxLogResult{{GasUsed: "0x5208"}, {GasUsed: "0x5208", Error: "execution reverted"}},
+	}
+
+	require.NoError(t, RequireMinProfit(big.NewInt(100)).Check(ctx, bundle, res))
+	require.Error(t, RequireMinProfit(big.NewInt(101)).Check(ctx, bundle, res))
+
+	require.NoError(t, RequireMaxGasUsed(42000).Check(ctx, bundle, res))
+	require.Error(t, RequireMaxGasUsed(41999).Check(ctx, bundle, res))
+
+	require.ErrorContains(t, RequireNoReverts().Check(ctx, bundle, res), "transaction 1 reverted")
+	require.NoError(t, RequireNoReverts(1).Check(ctx, bundle, res))
+	// A revert inside a nested bundle fails the transaction that carries it.
+	nested := &SimulateResponse{Success: true, Logs: []TxLogResult{{BundleLogs: []TxLogResult{{Error: "execution reverted"}}}, {}}}
+	require.ErrorContains(t, RequireNoReverts().Check(ctx, bundle, nested), "transaction 0 reverted")
+	require.NoError(t, RequireNoReverts(0).Check(ctx, bundle, nested))
+
+	// Only the first transaction executed, so the sender is down 1 wei.
+	require.NoError(t, RequireAssetChange(sender, ETHAsset, big.NewInt(-1)).Check(ctx, bundle, res))
+	require.ErrorContains(t, RequireAssetChange(sender, ETHAsset, common.Big0).Check(ctx, bundle, res), "nets -1 of eth")
+}
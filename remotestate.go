@@ -0,0 +1,136 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/holiman/uint256"
+)
+
+// remoteStateReader implements state.Reader by fetching accounts, code and storage from an Ethereum node
+// at a fixed block, the first time each one is read. Fetched values are cached for the reader's lifetime.
+type remoteStateReader struct {
+	// ctx bounds every request, state.Reader methods do not take a context.
+	ctx   context.Context
+	ethC  *ethclient.Client
+	block *big.Int
+
+	mu       sync.Mutex
+	accounts map[common.Address]*remoteAccount
+	storage  map[common.Address]map[common.Hash]common.Hash
+}
+
+// remoteAccount is a cached account. account is nil when the account does not exist.
+type remoteAccount struct {
+	account *types.StateAccount
+	code    []byte
+}
+
+var _ state.Reader = (*remoteStateReader)(nil)
+
+func newRemoteStateReader(ctx context.Context, ethC *ethclient.Client, block *big.Int) *remoteStateReader {
+	return &remoteStateReader{
+		ctx:      ctx,
+		ethC:     ethC,
+		block:    block,
+		accounts: make(map[common.Address]*remoteAccount),
+		storage:  make(map[common.Address]map[common.Hash]common.Hash),
+	}
+}
+
+// Account returns a copy of the account, or nil when it has no nonce, balance or code.
+// The storage root is not fetched and is always reported empty.
+func (r *remoteStateReader) Account(addr common.Address) (*types.StateAccount, error) {
+	acc, err := r.fetchAccount(addr)
+	if err != nil || acc.account == nil {
+		return nil, err
+	}
+	return acc.account.Copy(), nil
+}
+
+func (r *remoteStateReader) Code(addr common.Address, _ common.Hash) ([]byte, error) {
+	acc, err := r.fetchAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	return common.CopyBytes(acc.code), nil
+}
+
+func (r *remoteStateReader) CodeSize(addr common.Address, _ common.Hash) (int, error) {
+	acc, err := r.fetchAccount(addr)
+	if err != nil {
+		return 0, err
+	}
+	return len(acc.code), nil
+}
+
+func (r *remoteStateReader) Storage(addr common.Address, slot common.Hash) (common.Hash, error) {
+	r.mu.Lock()
+	if value, ok := r.storage[addr][slot]; ok {
+		r.mu.Unlock()
+		return value, nil
+	}
+	r.mu.Unlock()
+
+	raw, err := r.ethC.StorageAt(r.ctx, addr, slot, r.block)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get storage %s of %s: %w", slot.Hex(), addr.Hex(), err)
+	}
+	value := common.BytesToHash(raw)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.storage[addr] == nil {
+		r.storage[addr] = make(map[common.Hash]common.Hash)
+	}
+	r.storage[addr][slot] = value
+	return value, nil
+}
+
+func (r *remoteStateReader) fetchAccount(addr common.Address) (*remoteAccount, error) {
+	r.mu.Lock()
+	if acc, ok := r.accounts[addr]; ok {
+		r.mu.Unlock()
+		return acc, nil
+	}
+	r.mu.Unlock()
+
+	balance, err := r.ethC.BalanceAt(r.ctx, addr, r.block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance of %s: %w", addr.Hex(), err)
+	}
+	nonce, err := r.ethC.NonceAt(r.ctx, addr, r.block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce of %s: %w", addr.Hex(), err)
+	}
+	code, err := r.ethC.CodeAt(r.ctx, addr, r.block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code of %s: %w", addr.Hex(), err)
+	}
+
+	acc := &remoteAccount{code: code}
+	if nonce != 0 || balance.Sign() != 0 || len(code) != 0 {
+		codeHash := types.EmptyCodeHash
+		if len(code) != 0 {
+			codeHash = crypto.Keccak256Hash(code)
+		}
+		acc.account = &types.StateAccount{
+			Nonce:    nonce,
+			Balance:  uint256.MustFromBig(balance),
+			Root:     types.EmptyRootHash,
+			CodeHash: codeHash.Bytes(),
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accounts[addr] = acc
+	return acc, nil
+}
@@ -0,0 +1,231 @@
+package flashbot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RevertKind tells how revert data was decoded.
+type RevertKind string
+
+const (
+	// RevertKindError is a require or revert with a message, encoded as Error(string).
+	RevertKindError RevertKind = "error"
+	// RevertKindPanic is a Solidity panic, encoded as Panic(uint256).
+	RevertKindPanic RevertKind = "panic"
+	// RevertKindCustom is a custom Solidity error found in the ABI registry.
+	RevertKindCustom RevertKind = "custom"
+	// RevertKindUnknown is revert data that could not be decoded, including empty data.
+	RevertKindUnknown RevertKind = "unknown"
+)
+
+var (
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// panicReasons names the panic codes the Solidity compiler emits.
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "out-of-bounds array access; popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+// RevertReason is decoded revert data.
+type RevertReason struct {
+	Kind RevertKind
+	// Message is the Error(string) message, the panic description, or the custom error with its arguments.
+	Message string
+	// PanicCode is set for panics.
+	PanicCode *big.Int
+	// Name and Args are set for custom errors.
+	Name string
+	Args map[string]interface{}
+	// Data is the raw revert data.
+	Data []byte
+}
+
+func (r *RevertReason) String() string {
+	return r.Message
+}
+
+// SimulationRevertError is returned by Simulate when a transaction that is not allowed to revert reverts.
+// The simulation response is returned alongside it.
+type SimulationRevertError struct {
+	// TxIndex is -1 when the simulator did not report which transaction reverted, as the relay does.
+	TxIndex int
+	TxHash  string
+	// Reason is nil when the simulator returned no revert data.
+	Reason *RevertReason
+	// Err is the simulator's error for the transaction.
+	Err string
+}
+
+func (e *SimulationRevertError) Error() string {
+	reason := e.Err
+	if e.Reason != nil && e.Reason.Kind != RevertKindUnknown {
+		reason = e.Reason.Message
+	}
+	if e.TxIndex < 0 {
+		return fmt.Sprintf("bundle reverted: %s", reason)
+	}
+	return fmt.Sprintf("transaction %d (%s) reverted: %s", e.TxIndex, e.TxHash, reason)
+}
+
+// SimulationInvalidError is returned by Simulate when the simulation failed without a revert, because a
+// transaction was invalid and never executed, for example for its nonce or its fees. The simulation response
+// is returned alongside it.
+type SimulationInvalidError struct {
+	// TxIndex is -1 when the simulator did not report which transaction was invalid, as the relay does.
+	TxIndex int
+	TxHash  string
+	// Err is the simulator's error.
+	Err string
+}
+
+func (e *SimulationInvalidError) Error() string {
+	if e.TxIndex < 0 {
+		return fmt.Sprintf("bundle is invalid: %s", e.Err)
+	}
+	return fmt.Sprintf("transaction %d (%s) is invalid: %s", e.TxIndex, e.TxHash, e.Err)
+}
+
+// DecodeRevert decodes revert data as Error(string), Panic(uint256) or a registered custom error.
+func (r *ABIRegistry) DecodeRevert(data []byte) *RevertReason {
+	reason := &RevertReason{Kind: RevertKindUnknown, Data: data, Message: "execution reverted"}
+	if len(data) < 4 {
+		return reason
+	}
+	selector, args := data[:4], data[4:]
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		if msg, err := abi.UnpackRevert(data); err == nil {
+			reason.Kind = RevertKindError
+			reason.Message = msg
+		}
+		return reason
+	case bytes.Equal(selector, panicSelector):
+		if len(args) != 32 {
+			return reason
+		}
+		code := new(big.Int).SetBytes(args)
+		reason.Kind = RevertKindPanic
+		reason.PanicCode = code
+		reason.Message = fmt.Sprintf("panic 0x%x", code)
+		if code.IsUint64() {
+			if name, ok := panicReasons[code.Uint64()]; ok {
+				reason.Message = fmt.Sprintf("panic: %s (0x%x)", name, code)
+			}
+		}
+		return reason
+	}
+
+	r.mu.RLock()
+	candidates := r.errors[[4]byte(selector)]
+	r.mu.RUnlock()
+	for _, e := range candidates {
+		values, err := e.Inputs.Unpack(args)
+		if err != nil {
+			continue
+		}
+		reason.Kind = RevertKindCustom
+		reason.Name = e.Name
+		reason.Args = make(map[string]interface{}, len(values))
+		formatted := make([]string, len(values))
+		for i, value := range values {
+			name := e.Inputs[i].Name
+			if name == "" {
+				name = fmt.Sprintf("arg%d", i)
+			}
+			reason.Args[name] = value
+			formatted[i] = fmt.Sprintf("%s: %v", name, value)
+		}
+		reason.Message = fmt.Sprintf("%s(%s)", e.Name, strings.Join(formatted, ", "))
+		return reason
+	}
+	reason.Message = fmt.Sprintf("execution reverted with unknown error %s", hexutil.Encode(selector))
+	return reason
+}
+
+// decodeReverts attaches a decoded reason to every reverted transaction of the simulation, nested bundles
+// included. For a failed simulation it returns a *SimulationRevertError for the first transaction that
+// reverted without being allowed to, or a *SimulationInvalidError for the first one that never executed.
+// Without per-transaction results, as from the relay, the error is the bundle's, with TxIndex -1.
+func (f *flashbot) decodeReverts(bundle *Bundle, res *SimulateResponse) error {
+	if res.Revert != "" {
+		if data, err := hexutil.Decode(res.Revert); err == nil {
+			res.RevertReason = f.abis.DecodeRevert(data)
+		} else {
+			f.logger.WithError(err).Warn("failed to decode revert data")
+		}
+	}
+	var failure error
+	for i := range res.Logs {
+		txResult := &res.Logs[i]
+		txResult.walk(func(nested *TxLogResult) {
+			if nested.Revert == "" {
+				return
+			}
+			data, err := hexutil.Decode(nested.Revert)
+			if err != nil {
+				f.logger.WithError(err).Warn("failed to decode revert data")
+				return
+			}
+			nested.RevertReason = f.abis.DecodeRevert(data)
+		})
+		if failure != nil || txResult.Error == "" || bundle.canRevert(i) {
+			continue
+		}
+		if txResult.GasUsed == "" {
+			// The transaction is invalid and never executed.
+			failure = &SimulationInvalidError{TxIndex: i, TxHash: txResult.TxHash, Err: txResult.Error}
+			continue
+		}
+		failure = &SimulationRevertError{
+			TxIndex: i,
+			TxHash:  txResult.TxHash,
+			Reason:  txResult.RevertReason,
+			Err:     txResult.Error,
+		}
+	}
+	if failure == nil && !res.Success {
+		msg := res.Error
+		if msg == "" {
+			msg = res.ExecError
+		}
+		if msg == "" {
+			msg = "simulation failed"
+		}
+		if res.RevertReason != nil || strings.Contains(strings.ToLower(msg), "revert") {
+			failure = &SimulationRevertError{TxIndex: -1, Reason: res.RevertReason, Err: msg}
+		} else {
+			failure = &SimulationInvalidError{TxIndex: -1, Err: msg}
+		}
+	}
+	if failure == nil {
+		return nil
+	}
+	res.Error = failure.Error()
+	return failure
+}
+
+// isSimulationFailure reports whether err is a failed simulation, returned together with its response.
+func isSimulationFailure(err error) bool {
+	var revertErr *SimulationRevertError
+	var invalidErr *SimulationInvalidError
+	return errors.As(err, &revertErr) || errors.As(err, &invalidErr)
+}
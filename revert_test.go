@@ -0,0 +1,84 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRevert(t *testing.T) {
+	r := NewABIRegistry()
+	require.NoError(t, r.RegisterJSON(`[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`))
+
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	msg, err := abi.Arguments{{Type: stringType}}.Pack("ERC20: transfer amount exceeds balance")
+	require.NoError(t, err)
+	reason := r.DecodeRevert(append(append([]byte{}, errorSelector...), msg...))
+	require.Equal(t, RevertKindError, reason.Kind)
+	require.Equal(t, "ERC20: transfer amount exceeds balance", reason.String())
+
+	reason = r.DecodeRevert(append(append([]byte{}, panicSelector...), common.BigToHash(big.NewInt(0x11)).Bytes()...))
+	require.Equal(t, RevertKindPanic, reason.Kind)
+	require.Equal(t, big.NewInt(0x11), reason.PanicCode)
+	require.Equal(t, "panic: arithmetic underflow or overflow (0x11)", reason.Message)
+
+	custom := common.FromHex("0xcf479181")
+	custom = append(custom, common.BigToHash(big.NewInt(1)).Bytes()...)
+	custom = append(custom, common.BigToHash(big.NewInt(2)).Bytes()...)
+	reason = r.DecodeRevert(custom)
+	require.Equal(t, RevertKindCustom, reason.Kind)
+	require.Equal(t, "InsufficientBalance", reason.Name)
+	require.Equal(t, big.NewInt(2), reason.Args["required"])
+	require.Equal(t, "InsufficientBalance(available: 1, required: 2)", reason.Message)
+
+	require.Equal(t, RevertKindUnknown, r.DecodeRevert(nil).Kind)
+	require.Equal(t, RevertKindUnknown, r.DecodeRevert(common.FromHex("0xdeadbeef")).Kind)
+}
+
+func TestSimulateRelayFailure(t *testing.T) {
+	ctx := context.Background()
+	relay := newTestRelay(t)
+	sim := &MevSimResponse{}
+	relay.handle(methodMevSimBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		return sim, nil
+	})
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+	require.NoError(t, fb.ABIRegistry().RegisterJSON(`[{"type":"error","name":"Expired","inputs":[{"name":"deadline","type":"uint256"}]}]`))
+	tx, _ := newTestTx(t, 0)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx}}
+
+	// Expired(uint256) with deadline 5.
+	revert := append(crypto.Keccak256([]byte("Expired(uint256)"))[:4], common.BigToHash(big.NewInt(5)).Bytes()...)
+	*sim = MevSimResponse{Success: false, ExecError: "execution reverted", Revert: hexutil.Encode(revert)}
+	res, err := fb.Simulate(ctx, bundle, 101)
+	var revertErr *SimulationRevertError
+	require.ErrorAs(t, err, &revertErr)
+	require.Equal(t, -1, revertErr.TxIndex)
+	require.NotNil(t, revertErr.Reason)
+	require.Equal(t, "Expired", revertErr.Reason.Name)
+	require.EqualError(t, err, "bundle reverted: Expired(deadline: 5)")
+	require.Equal(t, err.Error(), res.Error)
+
+	*sim = MevSimResponse{Success: false, ExecError: "nonce too high"}
+	res, err = fb.Simulate(ctx, bundle, 101)
+	var invalidErr *SimulationInvalidError
+	require.ErrorAs(t, err, &invalidErr)
+	require.Equal(t, -1, invalidErr.TxIndex)
+	require.EqualError(t, err, "bundle is invalid: nonce too high")
+	require.False(t, res.Success)
+	require.Equal(t, FailureNonceTooHigh, Diagnose(bundle, res, err).Category)
+
+	*sim = MevSimResponse{Success: true}
+	_, err = fb.Simulate(ctx, bundle, 101)
+	require.NoError(t, err)
+}
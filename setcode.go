@@ -0,0 +1,218 @@
+package flashbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// AuthorizationSigner signs EIP-7702 set-code authorizations on behalf of a single account.
+type AuthorizationSigner interface {
+	// Address returns the account (authority) the signer signs for.
+	Address() common.Address
+	// SignAuthorization returns a signed copy of auth.
+	SignAuthorization(ctx context.Context, auth types.SetCodeAuthorization) (types.SetCodeAuthorization, error)
+}
+
+// AuthorizationError describes a set-code authorization that would be skipped when its transaction executes.
+type AuthorizationError struct {
+	// TxIndex is the index of the transaction in the bundle, AuthIndex the index in its authorization list.
+	TxIndex   int
+	AuthIndex int
+	// Authority is the recovered signer, zero when recovery failed.
+	Authority common.Address
+	Reason    string
+}
+
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("authorization %d of transaction %d (authority %s): %s", e.AuthIndex, e.TxIndex, e.Authority.Hex(), e.Reason)
+}
+
+// SignAuthorization builds and signs an authorization delegating the signer's account to delegate.
+// The nonce is reserved from the client's nonce manager, or is the authority's pending nonce without one.
+// sendsTx must be true when the authority also sends the set-code transaction carrying the authorization,
+// since the sender nonce is incremented before authorizations apply; the nonce of that transaction,
+// auth.Nonce-1, is then reserved too. Reserved nonces are released when signing fails.
+func (f *flashbot) SignAuthorization(ctx context.Context, signer AuthorizationSigner, delegate common.Address, sendsTx bool) (types.SetCodeAuthorization, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.SignAuthorization")
+	defer span.End()
+
+	if f.ethC == nil {
+		span.SetStatus(codes.Error, ErrEthClientNotConfigured.Error())
+		return types.SetCodeAuthorization{}, ErrEthClientNotConfigured
+	}
+	chainID, err := f.getChainID(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return types.SetCodeAuthorization{}, err
+	}
+	authority := signer.Address()
+	count := 1
+	if sendsTx {
+		count = 2
+	}
+	var first uint64
+	if f.nonces != nil {
+		first, err = f.nonces.Reserve(ctx, authority, count)
+	} else {
+		first, err = f.ethC.PendingNonceAt(ctx, authority)
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return types.SetCodeAuthorization{}, fmt.Errorf("failed to get authority nonce: %w", err)
+	}
+	auth, err := signer.SignAuthorization(ctx, types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(chainID),
+		Address: delegate,
+		Nonce:   first + uint64(count-1),
+	})
+	if err != nil {
+		if f.nonces != nil {
+			f.nonces.Release(authority, reservedRange(first, count)...)
+		}
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return types.SetCodeAuthorization{}, fmt.Errorf("failed to sign authorization: %w", err)
+	}
+	span.SetStatus(codes.Ok, "authorization signed successfully")
+	return auth, nil
+}
+
+// ValidateAuthorizations replays the nonce changes of the bundle on top of the pending nonces and checks that
+// every set-code authorization recovers to an authority, targets this chain (or any chain), and carries the
+// authority's nonce at the point it executes. Invalid authorizations are silently skipped on chain, so
+// each one is reported as an *AuthorizationError, joined into the returned error.
+func (f *flashbot) ValidateAuthorizations(ctx context.Context, bundle *Bundle) error {
+	ctx, span := f.tracer.Start(ctx, "flashbot.ValidateAuthorizations")
+	defer span.End()
+
+	if f.ethC == nil {
+		span.SetStatus(codes.Error, ErrEthClientNotConfigured.Error())
+		return ErrEthClientNotConfigured
+	}
+	chainID, err := f.getChainID(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return err
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
+	nonces := make(map[common.Address]uint64)
+	nonceOf := func(addr common.Address) (uint64, error) {
+		if nonce, ok := nonces[addr]; ok {
+			return nonce, nil
+		}
+		// The pending nonce, like SignAuthorization: the bundle lands after the mempool transactions.
+		nonce, err := f.ethC.PendingNonceAt(ctx, addr)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get nonce of %s: %w", addr.Hex(), err)
+		}
+		nonces[addr] = nonce
+		return nonce, nil
+	}
+
+	var errs []error
+	for i, tx := range bundle.Transactions {
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+		}
+		if _, err := nonceOf(sender); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return err
+		}
+		// Authorizations only see the sender nonce after it was incremented for the transaction itself.
+		nonces[sender] = tx.Nonce() + 1
+
+		for j, auth := range tx.SetCodeAuthorizations() {
+			authErr := &AuthorizationError{TxIndex: i, AuthIndex: j}
+			authority, err := auth.Authority()
+			if err != nil {
+				authErr.Reason = fmt.Sprintf("invalid signature: %v", err)
+				errs = append(errs, authErr)
+				continue
+			}
+			authErr.Authority = authority
+			if !auth.ChainID.IsZero() && auth.ChainID.CmpBig(chainID) != 0 {
+				authErr.Reason = fmt.Sprintf("chain id %s does not match %s", auth.ChainID.String(), chainID.String())
+				errs = append(errs, authErr)
+				continue
+			}
+			if auth.Nonce == math.MaxUint64 {
+				authErr.Reason = "nonce overflows"
+				errs = append(errs, authErr)
+				continue
+			}
+			nonce, err := nonceOf(authority)
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+				span.RecordError(err)
+				return err
+			}
+			if auth.Nonce != nonce {
+				authErr.Reason = fmt.Sprintf("nonce %d does not match account nonce %d", auth.Nonce, nonce)
+				errs = append(errs, authErr)
+				continue
+			}
+			nonces[authority] = nonce + 1
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	span.SetStatus(codes.Ok, "authorizations validated successfully")
+	return nil
+}
+
+// authorities returns the recovered authorities of every set-code authorization in the bundle.
+// Authorizations whose signature cannot be recovered are ignored, they cannot change any nonce.
+func (b *Bundle) authorities() map[common.Address]struct{} {
+	authorities := make(map[common.Address]struct{})
+	for _, tx := range b.Transactions {
+		for _, auth := range tx.SetCodeAuthorizations() {
+			if authority, err := auth.Authority(); err == nil {
+				authorities[authority] = struct{}{}
+			}
+		}
+	}
+	return authorities
+}
+
+// nextNonce returns the nonce addr uses after the bundle executes, counting both the transactions it sends
+// and the authorizations it signs. ok is false when the bundle does not touch the nonce of addr.
+func (b *Bundle) nextNonce(chainID *big.Int, addr common.Address) (nonce uint64, ok bool, err error) {
+	signer := types.LatestSignerForChainID(chainID)
+	for i, tx := range b.Transactions {
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+		}
+		if sender == addr && (!ok || tx.Nonce() >= nonce) {
+			nonce, ok = tx.Nonce()+1, true
+		}
+		for _, auth := range tx.SetCodeAuthorizations() {
+			authority, err := auth.Authority()
+			if err != nil || authority != addr {
+				continue
+			}
+			if !ok || auth.Nonce >= nonce {
+				nonce, ok = auth.Nonce+1, true
+			}
+		}
+	}
+	return nonce, ok, nil
+}
@@ -0,0 +1,149 @@
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSetCodeTx(t *testing.T, senderKey *ecdsa.PrivateKey, nonce uint64, auths ...types.SetCodeAuthorization) *types.Transaction {
+	t.Helper()
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	tx, err := types.SignNewTx(senderKey, types.LatestSignerForChainID(big.NewInt(SepoliaChainID)), &types.SetCodeTx{
+		ChainID:   uint256.NewInt(SepoliaChainID),
+		Nonce:     nonce,
+		To:        to,
+		Gas:       100_000,
+		GasFeeCap: uint256.NewInt(2e9),
+		GasTipCap: uint256.NewInt(1e9),
+		AuthList:  auths,
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func TestValidateAuthorizations(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC))
+	require.NoError(t, err)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewPrivateKeySigner(key)
+	node.nonces[signer.Address()] = 4
+	delegate := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	// The authority sends the set-code transaction itself, so the authorization uses nonce+1.
+	auth, err := fb.SignAuthorization(ctx, signer, delegate, true)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), auth.Nonce)
+	tx := newTestSetCodeTx(t, key, 4, auth)
+	require.NoError(t, fb.ValidateAuthorizations(ctx, &Bundle{Transactions: []*types.Transaction{tx}}))
+
+	next, ok, err := (&Bundle{Transactions: []*types.Transaction{tx}}).nextNonce(big.NewInt(SepoliaChainID), signer.Address())
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(6), next)
+
+	// Signed without accounting for the sender nonce, and for another chain.
+	stale, err := fb.SignAuthorization(ctx, signer, delegate, false)
+	require.NoError(t, err)
+	foreign, err := types.SignSetCode(key, types.SetCodeAuthorization{ChainID: *uint256.NewInt(1), Address: delegate, Nonce: 6})
+	require.NoError(t, err)
+	err = fb.ValidateAuthorizations(ctx, &Bundle{Transactions: []*types.Transaction{newTestSetCodeTx(t, key, 4, stale, foreign)}})
+	require.Error(t, err)
+
+	var authErr *AuthorizationError
+	require.True(t, errors.As(err, &authErr))
+	require.Equal(t, 0, authErr.AuthIndex)
+	require.Equal(t, signer.Address(), authErr.Authority)
+	require.Contains(t, err.Error(), "chain id 1 does not match")
+}
+
+func TestValidateAuthorizationsPendingNonce(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC))
+	require.NoError(t, err)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewPrivateKeySigner(key)
+	delegate := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	// The authority has one transaction waiting in the mempool.
+	node.nonces[signer.Address()] = 4
+	node.pending[signer.Address()] = 5
+
+	auth, err := fb.SignAuthorization(ctx, signer, delegate, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), auth.Nonce)
+
+	sponsorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx := newTestSetCodeTx(t, sponsorKey, 0, auth)
+	require.NoError(t, fb.ValidateAuthorizations(ctx, &Bundle{Transactions: []*types.Transaction{tx}}))
+}
+
+func TestSignAuthorizationReservesNonces(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC))
+	require.NoError(t, err)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewPrivateKeySigner(key)
+	authority := signer.Address()
+	node.nonces[authority] = 4
+	delegate := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	// A bundle of the authority is already in flight with nonce 4.
+	inFlight, err := fb.NonceManager().Reserve(ctx, authority, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), inFlight)
+
+	auth, err := fb.SignAuthorization(ctx, signer, delegate, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), auth.Nonce)
+	// Nonce 6 is reserved for the set-code transaction, 7 for its authorization.
+	sent, err := fb.SignAuthorization(ctx, signer, delegate, true)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), sent.Nonce)
+
+	// The bundle carrying the first authorization expires, its authority nonce is given back.
+	sponsorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx := newTestSetCodeTx(t, sponsorKey, 0, auth)
+	sponsor := crypto.PubkeyToAddress(sponsorKey.PublicKey)
+	fb.(*flashbot).settleNonces(ctx, &Bundle{Transactions: []*types.Transaction{tx}}, []common.Address{sponsor}, InclusionStatusExpired)
+	report, err := fb.NonceManager().Report(ctx, authority)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4, 6, 7}, report.Reserved)
+	require.Equal(t, []uint64{5}, report.Gaps)
+}
+
+func TestSponsorBundleRejectsSponsorAuthority(t *testing.T) {
+	ctx := context.Background()
+	_, ethC := newTestNode(t)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC))
+	require.NoError(t, err)
+
+	sponsorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	userKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth, err := types.SignSetCode(sponsorKey, types.SetCodeAuthorization{ChainID: *uint256.NewInt(SepoliaChainID)})
+	require.NoError(t, err)
+
+	_, err = fb.SponsorBundle(ctx, []*types.Transaction{newTestSetCodeTx(t, userKey, 0, auth)}, NewPrivateKeySigner(sponsorKey))
+	require.ErrorContains(t, err, "set-code authorization")
+}
@@ -0,0 +1,57 @@
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TxSigner signs transactions on behalf of a single account.
+// Implementations can wrap a local key, a keystore, or a remote signer such as a KMS.
+type TxSigner interface {
+	// Address returns the account the signer signs for.
+	Address() common.Address
+	// SignTx returns a signed copy of tx for the given chain.
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// privateKeySigner implements TxSigner with an in-memory private key.
+type privateKeySigner struct {
+	pk      *ecdsa.PrivateKey
+	address common.Address
+}
+
+var (
+	_ TxSigner            = (*privateKeySigner)(nil)
+	_ AuthorizationSigner = (*privateKeySigner)(nil)
+)
+
+// PrivateKeySigner signs transactions and EIP-7702 authorizations with an in-memory private key.
+type PrivateKeySigner interface {
+	TxSigner
+	AuthorizationSigner
+}
+
+// NewPrivateKeySigner returns a signer backed by an in-memory private key.
+func NewPrivateKeySigner(pk *ecdsa.PrivateKey) PrivateKeySigner {
+	return &privateKeySigner{
+		pk:      pk,
+		address: crypto.PubkeyToAddress(pk.PublicKey),
+	}
+}
+
+func (s *privateKeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *privateKeySigner) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.pk)
+}
+
+func (s *privateKeySigner) SignAuthorization(_ context.Context, auth types.SetCodeAuthorization) (types.SetCodeAuthorization, error) {
+	return types.SignSetCode(s.pk, auth)
+}
@@ -0,0 +1,234 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/sync/singleflight"
+)
+
+// HeadSource reports the number of the latest block. *ethclient.Client implements it.
+type HeadSource interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// SimulationCache remembers simulation results by bundle, target block and options, and merges concurrent
+// identical simulations into a single call. Every entry is dropped when a new head arrives, since the
+// state bundles are simulated on moves with it.
+// Heads are read from the HeadSource at most once per check interval, when the cache is used. Without a
+// source, callers report heads with NewHead.
+// A SimulationCache is safe for concurrent use.
+type SimulationCache struct {
+	heads      HeadSource
+	checkEvery time.Duration
+	group      singleflight.Group
+
+	mu        sync.Mutex
+	head      uint64
+	checkedAt time.Time
+	entries   map[common.Hash]*simCacheEntry
+}
+
+// simCacheEntry is a finished simulation. err is set for responses returned with an error, such as a
+// *SimulationRevertError; failures without a response are not cached.
+type simCacheEntry struct {
+	res *SimulateResponse
+	err error
+}
+
+// NewSimulationCache returns an empty cache reading heads from heads every checkEvery, 0 means the
+// default (1 second). heads may be nil.
+func NewSimulationCache(heads HeadSource, checkEvery time.Duration) *SimulationCache {
+	if checkEvery <= 0 {
+		checkEvery = defaultSimCacheHeadCheck
+	}
+	return &SimulationCache{
+		heads:      heads,
+		checkEvery: checkEvery,
+		entries:    make(map[common.Hash]*simCacheEntry),
+	}
+}
+
+// NewHead drops every entry when number is above the last head the cache saw.
+func (c *SimulationCache) NewHead(number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.newHead(number)
+}
+
+// Purge drops every entry.
+func (c *SimulationCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// Len returns the number of cached simulations.
+func (c *SimulationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// do returns the cached result for key, or runs simulate once for every concurrent caller with the same key.
+// simulate runs detached from the caller that started it, with its values but not its cancellation, so a
+// caller giving up does not fail the others; each caller stops waiting when its own context is done.
+// Every caller gets its own copy of the response.
+func (c *SimulationCache) do(ctx context.Context, key common.Hash, simulate func(ctx context.Context) (*SimulateResponse, error)) (*SimulateResponse, error) {
+	c.checkHead(ctx)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return entry.res.clone(), entry.err
+	}
+
+	ch := c.group.DoChan(key.Hex(), func() (interface{}, error) {
+		c.mu.Lock()
+		head := c.head
+		c.mu.Unlock()
+
+		// The call is shared: it must not fail for every caller when the one that started it gives up.
+		callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), simCacheCallTimeout)
+		defer cancel()
+		res, err := simulate(callCtx)
+		if res == nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		// A head that arrived during the simulation may have made it stale.
+		if c.head == head {
+			c.entries[key] = &simCacheEntry{res: res, err: err}
+		}
+		c.mu.Unlock()
+		return res, err
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-ch:
+		res, _ := result.Val.(*SimulateResponse)
+		return res.clone(), result.Err
+	}
+}
+
+// checkHead polls the head source once the check interval has passed. Failures keep the entries,
+// the next call checks again.
+func (c *SimulationCache) checkHead(ctx context.Context) {
+	if c.heads == nil {
+		return
+	}
+	c.mu.Lock()
+	due := time.Since(c.checkedAt) >= c.checkEvery
+	c.mu.Unlock()
+	if !due {
+		return
+	}
+	number, err := c.heads.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkedAt = time.Now()
+	c.newHead(number)
+}
+
+// newHead drops every entry on a new head. c.mu must be held.
+func (c *SimulationCache) newHead(number uint64) {
+	if number <= c.head {
+		return
+	}
+	c.head = number
+	clear(c.entries)
+}
+
+// simulationKey identifies a simulation by the bundle's transactions and revert flags, the target block
+// and the parameters and simulation overrides the options set.
+func simulationKey(bundle *Bundle, targetBlock uint64, opts []BundleOption) (common.Hash, error) {
+	params := mevSimBundleParams{
+		Version: "v0.1",
+		Inclusion: mevSendBundleInclusion{
+			Block: "0x" + strconv.FormatUint(targetBlock, 16),
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(&params); err != nil {
+			return common.Hash{}, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+	encoded, err := json.Marshal([]interface{}{params, params.simOptions})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to encode options: %w", err)
+	}
+	data := make([]byte, 0, len(bundle.Transactions)*(common.HashLength+1)+len(encoded))
+	for i, tx := range bundle.Transactions {
+		hash := tx.Hash()
+		data = append(data, hash[:]...)
+		if bundle.canRevert(i) {
+			data = append(data, 1)
+		} else {
+			data = append(data, 0)
+		}
+	}
+	return crypto.Keccak256Hash(data, encoded), nil
+}
+
+// clone deep-copies the response, so callers sharing a simulation cannot change each other's.
+func (r *SimulateResponse) clone() *SimulateResponse {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	c.Logs = cloneTxLogResults(r.Logs)
+	c.RevertReason = r.RevertReason.clone()
+	c.Raw = append(json.RawMessage(nil), r.Raw...)
+	return &c
+}
+
+func cloneTxLogResults(results []TxLogResult) []TxLogResult {
+	if results == nil {
+		return nil
+	}
+	c := make([]TxLogResult, len(results))
+	for i, result := range results {
+		c[i] = result
+		if result.TxLogs != nil {
+			c[i].TxLogs = make([]LogEntry, len(result.TxLogs))
+			for j, log := range result.TxLogs {
+				log.Topics = append([]string(nil), log.Topics...)
+				c[i].TxLogs[j] = log
+			}
+		}
+		c[i].BundleLogs = cloneTxLogResults(result.BundleLogs)
+		c[i].RevertReason = result.RevertReason.clone()
+	}
+	return c
+}
+
+// clone copies the reason; decoded argument values are shared.
+func (r *RevertReason) clone() *RevertReason {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	if r.PanicCode != nil {
+		c.PanicCode = new(big.Int).Set(r.PanicCode)
+	}
+	if r.Args != nil {
+		c.Args = make(map[string]interface{}, len(r.Args))
+		for k, v := range r.Args {
+			c.Args[k] = v
+		}
+	}
+	c.Data = append([]byte(nil), r.Data...)
+	return &c
+}
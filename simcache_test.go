@@ -0,0 +1,159 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testHeads is a HeadSource whose head the test moves.
+type testHeads struct {
+	head atomic.Uint64
+}
+
+func (h *testHeads) BlockNumber(context.Context) (uint64, error) {
+	return h.head.Load(), nil
+}
+
+func TestSimulationCache(t *testing.T) {
+	ctx := context.Background()
+	relay := newTestRelay(t)
+	release := make(chan struct{})
+	relay.handle(methodMevSimBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		<-release
+		return MevSimResponse{Success: true, Profit: "0x1"}, nil
+	})
+
+	heads := &testHeads{}
+	heads.head.Store(100)
+	cache := NewSimulationCache(heads, 1)
+	fb, err := New(ctx, WithRelayURL(relay.URL), WithSimulationCache(cache))
+	require.NoError(t, err)
+
+	tx, _ := newTestTx(t, 0)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx}}
+
+	// Concurrent identical simulations reach the relay once.
+	var wg sync.WaitGroup
+	results := make([]*SimulateResponse, 8)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = fb.Simulate(ctx, bundle, 101)
+		}(i)
+	}
+	require.Eventually(t, func() bool { return relay.callCount(methodMevSimBundle) == 1 }, time.Second, 10*time.Millisecond)
+	close(release)
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, relay.callCount(methodMevSimBundle))
+	require.Equal(t, "0x1", results[0].Profit)
+	require.NotSame(t, results[0], results[1])
+
+	// Later identical calls are served from the cache, other options or blocks are not.
+	_, err = fb.Simulate(ctx, bundle, 101)
+	require.NoError(t, err)
+	require.Equal(t, 1, relay.callCount(methodMevSimBundle))
+	_, err = fb.Simulate(ctx, bundle, 101, WithPrivacy(MevSendBundlePrivacy{Hints: []string{"hash"}}))
+	require.NoError(t, err)
+	_, err = fb.Simulate(ctx, bundle, 102)
+	require.NoError(t, err)
+	require.Equal(t, 3, relay.callCount(methodMevSimBundle))
+	require.Equal(t, 3, cache.Len())
+
+	// A new head expires every entry.
+	heads.head.Store(101)
+	_, err = fb.Simulate(ctx, bundle, 102)
+	require.NoError(t, err)
+	require.Equal(t, 4, relay.callCount(methodMevSimBundle))
+	require.Equal(t, 1, cache.Len())
+
+	cache.NewHead(102)
+	require.Zero(t, cache.Len())
+}
+
+func TestSimulationCacheLeaderCancelled(t *testing.T) {
+	ctx := context.Background()
+	cache := NewSimulationCache(nil, 0)
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	simulate := func(ctx context.Context) (*SimulateResponse, error) {
+		calls.Add(1)
+		close(started)
+		select {
+		case <-release:
+			return &SimulateResponse{Success: true}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := cache.do(leaderCtx, common.Hash{1}, simulate)
+		leaderErr <- err
+	}()
+	<-started
+	type result struct {
+		res *SimulateResponse
+		err error
+	}
+	follower := make(chan result, 1)
+	go func() {
+		res, err := cache.do(ctx, common.Hash{1}, simulate)
+		follower <- result{res, err}
+	}()
+	time.Sleep(20 * time.Millisecond) // let the follower join the call
+
+	// The caller that started the call gives up, the follower still gets the result.
+	cancel()
+	require.ErrorIs(t, <-leaderErr, context.Canceled)
+	close(release)
+	got := <-follower
+	require.NoError(t, got.err)
+	require.True(t, got.res.Success)
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestSimulateResponseClone(t *testing.T) {
+	original := &SimulateResponse{
+		Logs: []TxLogResult{{
+			TxLogs:       []LogEntry{{Address: "0x01", Topics: []string{"0xaa"}}},
+			BundleLogs:   []TxLogResult{{TxLogs: []LogEntry{{Topics: []string{"0xbb"}}}}},
+			RevertReason: &RevertReason{Message: "nope", PanicCode: big.NewInt(1), Args: map[string]interface{}{"a": 1}, Data: []byte{1}},
+		}},
+		RevertReason: &RevertReason{Message: "nope", Data: []byte{2}},
+		Raw:          json.RawMessage(`{}`),
+	}
+	c := original.clone()
+	c.Logs[0].TxLogs[0].Topics[0] = "0xcc"
+	c.Logs[0].BundleLogs[0].TxLogs[0].Topics[0] = "0xcc"
+	c.Logs[0].RevertReason.Message = "changed"
+	c.Logs[0].RevertReason.PanicCode.SetInt64(2)
+	c.Logs[0].RevertReason.Args["a"] = 2
+	c.Logs[0].RevertReason.Data[0] = 9
+	c.RevertReason.Data[0] = 9
+	c.Raw[0] = '['
+
+	require.Equal(t, "0xaa", original.Logs[0].TxLogs[0].Topics[0])
+	require.Equal(t, "0xbb", original.Logs[0].BundleLogs[0].TxLogs[0].Topics[0])
+	require.Equal(t, "nope", original.Logs[0].RevertReason.Message)
+	require.Equal(t, int64(1), original.Logs[0].RevertReason.PanicCode.Int64())
+	require.Equal(t, 1, original.Logs[0].RevertReason.Args["a"])
+	require.Equal(t, []byte{1}, original.Logs[0].RevertReason.Data)
+	require.Equal(t, []byte{2}, original.RevertReason.Data)
+	require.Equal(t, `{}`, string(original.Raw))
+}
@@ -0,0 +1,114 @@
+package flashbot
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrSimOverridesUnsupported is returned by Simulate when WithSimOptions is given and the simulator set with
+// WithSimulator is not an OverridableSimulator.
+var ErrSimOverridesUnsupported = errors.New("simulator does not support simulation overrides")
+
+// MevSimBundleOptions overrides the block mev_simBundle simulates on. It is sent as the second parameter
+// of mev_simBundle; fields left nil keep the relay's defaults, derived from the target block.
+type MevSimBundleOptions struct {
+	// ParentBlock is the block whose state the bundle runs on, a hex number or a tag such as "latest".
+	ParentBlock *string         `json:"parentBlock,omitempty"`
+	BlockNumber *hexutil.Big    `json:"blockNumber,omitempty"`
+	Coinbase    *common.Address `json:"coinbase,omitempty"`
+	Timestamp   *hexutil.Uint64 `json:"timestamp,omitempty"`
+	GasLimit    *hexutil.Uint64 `json:"gasLimit,omitempty"`
+	BaseFee     *hexutil.Big    `json:"baseFee,omitempty"`
+	// Timeout is the simulation timeout in seconds.
+	Timeout *int64 `json:"timeout,omitempty"`
+}
+
+// SimOption sets a simulation override.
+type SimOption func(*MevSimBundleOptions) error
+
+// WithSimOptions makes Simulate send the overrides with mev_simBundle, or apply them to the simulator set with
+// WithSimulator. They are ignored by Broadcast, except for its pre-flight simulation.
+func WithSimOptions(opts ...SimOption) BundleOption {
+	return func(params *mevSimBundleParams) error {
+		if params.simOptions == nil {
+			params.simOptions = &MevSimBundleOptions{}
+		}
+		for _, opt := range opts {
+			if err := opt(params.simOptions); err != nil {
+				return fmt.Errorf("failed to apply simulation option: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// WithSimParentBlock simulates on the state after block.
+func WithSimParentBlock(block uint64) SimOption {
+	return func(o *MevSimBundleOptions) error {
+		parent := "0x" + strconv.FormatUint(block, 16)
+		o.ParentBlock = &parent
+		return nil
+	}
+}
+
+// WithSimBlockNumber sets the number of the simulated block.
+func WithSimBlockNumber(number uint64) SimOption {
+	return func(o *MevSimBundleOptions) error {
+		o.BlockNumber = (*hexutil.Big)(new(big.Int).SetUint64(number))
+		return nil
+	}
+}
+
+// WithSimCoinbase sets the coinbase of the simulated block, for example the fee recipient of a builder
+// from the BuilderRegistry, so payments to block.coinbase go where they would in that builder's block.
+func WithSimCoinbase(coinbase common.Address) SimOption {
+	return func(o *MevSimBundleOptions) error {
+		o.Coinbase = &coinbase
+		return nil
+	}
+}
+
+// WithSimTimestamp sets the timestamp of the simulated block, in seconds.
+func WithSimTimestamp(timestamp uint64) SimOption {
+	return func(o *MevSimBundleOptions) error {
+		o.Timestamp = (*hexutil.Uint64)(&timestamp)
+		return nil
+	}
+}
+
+// WithSimGasLimit sets the gas limit of the simulated block.
+func WithSimGasLimit(gasLimit uint64) SimOption {
+	return func(o *MevSimBundleOptions) error {
+		o.GasLimit = (*hexutil.Uint64)(&gasLimit)
+		return nil
+	}
+}
+
+// WithSimBaseFee sets the base fee of the simulated block, in wei.
+func WithSimBaseFee(baseFee *big.Int) SimOption {
+	return func(o *MevSimBundleOptions) error {
+		if baseFee == nil || baseFee.Sign() < 0 {
+			return fmt.Errorf("base fee must be a non-negative amount")
+		}
+		o.BaseFee = (*hexutil.Big)(new(big.Int).Set(baseFee))
+		return nil
+	}
+}
+
+// WithSimTimeout sets how long the relay may spend on the simulation, rounded down to whole seconds.
+func WithSimTimeout(timeout time.Duration) SimOption {
+	return func(o *MevSimBundleOptions) error {
+		seconds := int64(timeout / time.Second)
+		if seconds <= 0 {
+			return fmt.Errorf("timeout must be at least one second")
+		}
+		o.Timeout = &seconds
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateWithSimOptions(t *testing.T) {
+	ctx := context.Background()
+	relay := newTestRelay(t)
+	var received []json.RawMessage
+	relay.handle(methodMevSimBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		received = params
+		return MevSimResponse{Success: true}, nil
+	})
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+	tx, _ := newTestTx(t, 0)
+	bundle := &Bundle{Transactions: []*types.Transaction{tx}}
+
+	_, err = fb.Simulate(ctx, bundle, 101)
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+
+	titan, _ := DefaultBuilderRegistry().Lookup("titan")
+	opts := WithSimOptions(
+		WithSimParentBlock(100),
+		WithSimBlockNumber(101),
+		WithSimCoinbase(titan),
+		WithSimTimestamp(1700000000),
+		WithSimGasLimit(30_000_000),
+		WithSimBaseFee(big.NewInt(1_125_000_000)),
+		WithSimTimeout(5*time.Second),
+	)
+	_, err = fb.Simulate(ctx, bundle, 101, opts)
+	require.NoError(t, err)
+	require.Len(t, received, 2)
+	require.JSONEq(t, `{
+		"parentBlock": "0x64",
+		"blockNumber": "0x65",
+		"coinbase": "0x4838b106fce9647bdf1e7877bf73ce8b0bad5f97",
+		"timestamp": "0x6553f100",
+		"gasLimit": "0x1c9c380",
+		"baseFee": "0x430e2340",
+		"timeout": 5
+	}`, string(received[1]))
+
+	_, err = fb.Simulate(ctx, bundle, 101, WithSimOptions(WithSimTimeout(time.Millisecond)))
+	require.ErrorContains(t, err, "timeout must be at least one second")
+
+	// Overrides are part of the simulation cache key.
+	plain, err := simulationKey(bundle, 101, nil)
+	require.NoError(t, err)
+	overridden, err := simulationKey(bundle, 101, []BundleOption{opts})
+	require.NoError(t, err)
+	require.NotEqual(t, plain, overridden)
+}
@@ -0,0 +1,123 @@
+package flashbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// SimNumbers holds the quantities of a simulation response, parsed.
+type SimNumbers struct {
+	StateBlock      uint64
+	GasUsed         uint64
+	MevGasPrice     *big.Int
+	Profit          *big.Int
+	RefundableValue *big.Int
+}
+
+// UnmarshalJSON decodes a mev_simBundle result, accepting quantities as hex strings, decimal strings or
+// JSON numbers, and keeps the raw JSON in Raw.
+func (r *MevSimResponse) UnmarshalJSON(data []byte) error {
+	type plain MevSimResponse
+	aux := struct {
+		*plain
+		StateBlock      json.RawMessage `json:"stateBlock"`
+		MevGasPrice     json.RawMessage `json:"mevGasPrice"`
+		Profit          json.RawMessage `json:"profit"`
+		RefundableValue json.RawMessage `json:"refundableValue"`
+		GasUsed         json.RawMessage `json:"gasUsed"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	for _, q := range []struct {
+		name string
+		raw  json.RawMessage
+		dst  *string
+	}{
+		{"stateBlock", aux.StateBlock, &r.StateBlock},
+		{"mevGasPrice", aux.MevGasPrice, &r.MevGasPrice},
+		{"profit", aux.Profit, &r.Profit},
+		{"refundableValue", aux.RefundableValue, &r.RefundableValue},
+		{"gasUsed", aux.GasUsed, &r.GasUsed},
+	} {
+		v, err := quantityString(q.raw)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", q.name, err)
+		}
+		*q.dst = v
+	}
+	r.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Numbers parses the block, gas and wei quantities of the response. Missing quantities are zero.
+func (r *MevSimResponse) Numbers() (*SimNumbers, error) {
+	var n SimNumbers
+	var err error
+	if n.StateBlock, err = parseUint64(r.StateBlock); err != nil {
+		return nil, fmt.Errorf("invalid state block: %w", err)
+	}
+	if n.GasUsed, err = parseUint64(r.GasUsed); err != nil {
+		return nil, fmt.Errorf("invalid gas used: %w", err)
+	}
+	if n.MevGasPrice, err = parseBigInt(r.MevGasPrice); err != nil {
+		return nil, fmt.Errorf("invalid mev gas price: %w", err)
+	}
+	if n.Profit, err = parseBigInt(r.Profit); err != nil {
+		return nil, fmt.Errorf("invalid profit: %w", err)
+	}
+	if n.RefundableValue, err = parseBigInt(r.RefundableValue); err != nil {
+		return nil, fmt.Errorf("invalid refundable value: %w", err)
+	}
+	return &n, nil
+}
+
+// quantityString returns a JSON string or number as text, and "" for a missing or null value.
+func quantityString(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	if raw[0] == '"' {
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return "", err
+	}
+	return n.String(), nil
+}
+
+// parseUint64 parses a relay quantity that must fit in 64 bits.
+func parseUint64(s string) (uint64, error) {
+	v, err := parseBigInt(s)
+	if err != nil {
+		return 0, err
+	}
+	if !v.IsUint64() {
+		return 0, fmt.Errorf("number %q out of range", s)
+	}
+	return v.Uint64(), nil
+}
+
+// walk calls fn on the result and, depth first, on every result of its nested bundle.
+func (r *TxLogResult) walk(fn func(*TxLogResult)) {
+	fn(r)
+	for i := range r.BundleLogs {
+		r.BundleLogs[i].walk(fn)
+	}
+}
+
+// failed reports whether a transaction of the result, nested ones included, failed or reverted, and returns
+// the first one that did.
+func (r *TxLogResult) failed() (*TxLogResult, bool) {
+	var first *TxLogResult
+	r.walk(func(res *TxLogResult) {
+		if first == nil && (res.Error != "" || res.Revert != "") {
+			first = res
+		}
+	})
+	return first, first != nil
+}
@@ -0,0 +1,67 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMevSimResponse(t *testing.T) {
+	ctx := context.Background()
+	relay := newTestRelay(t)
+	relay.handle(methodMevSimBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		return json.RawMessage(`{
+			"success": false,
+			"stateBlock": "0x64",
+			"mevGasPrice": 1000000000,
+			"profit": "21000000000000",
+			"refundableValue": "0x0",
+			"gasUsed": 21000,
+			"logs": [
+				{"txLogs": [{"address": "0x0000000000000000000000000000000000000001", "topics": [], "data": "0x"}]},
+				{"bundleLogs": [{"txLogs": []}, {"txLogs": []}]}
+			],
+			"execError": "execution reverted",
+			"revert": "0x4e487b710000000000000000000000000000000000000000000000000000000000000011",
+			"builderHint": "new"
+		}`), nil
+	})
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+	tx, _ := newTestTx(t, 0)
+
+	res, err := fb.Simulate(ctx, &Bundle{Transactions: []*types.Transaction{tx}}, 101)
+	var revertErr *SimulationRevertError
+	require.ErrorAs(t, err, &revertErr)
+	require.Equal(t, -1, revertErr.TxIndex)
+	require.Same(t, res.RevertReason, revertErr.Reason)
+	require.ErrorContains(t, err, "bundle reverted: panic: arithmetic underflow or overflow")
+	require.False(t, res.Success)
+	require.Equal(t, "1000000000", res.MevGasPrice)
+	require.Equal(t, "21000", res.GasUsed)
+	require.Len(t, res.Logs, 2)
+	require.Len(t, res.Logs[0].TxLogs, 1)
+	require.Len(t, res.Logs[1].BundleLogs, 2)
+	require.Equal(t, "execution reverted", res.ExecError)
+	require.NotNil(t, res.RevertReason)
+	require.Equal(t, RevertKindPanic, res.RevertReason.Kind)
+	require.Contains(t, string(res.Raw), `"builderHint":"new"`)
+
+	numbers, err := res.Numbers()
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), numbers.StateBlock)
+	require.Equal(t, uint64(21000), numbers.GasUsed)
+	require.Zero(t, numbers.MevGasPrice.Cmp(big.NewInt(1e9)))
+	require.Zero(t, numbers.Profit.Cmp(big.NewInt(21e12)))
+	require.Zero(t, numbers.RefundableValue.Sign())
+
+	err = RequireSimulationSuccess().Check(ctx, nil, res)
+	require.EqualError(t, err, "simulation failed: panic: arithmetic underflow or overflow (0x11)")
+
+	var bad MevSimResponse
+	require.ErrorContains(t, json.Unmarshal([]byte(`{"gasUsed": true}`), &bad), "invalid gasUsed")
+}
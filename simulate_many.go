@@ -0,0 +1,227 @@
+package flashbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/time/rate"
+)
+
+// ErrSimulationDeadline is the error of candidates SimulateMany did not finish before the block deadline.
+var ErrSimulationDeadline = errors.New("block deadline reached before the simulation finished")
+
+// SimulationScorer ranks a successful simulation, the higher the better.
+type SimulationScorer func(bundle *Bundle, res *SimulateResponse) (*big.Int, error)
+
+// ScoreByProfit scores a simulation by the profit it pays the builder.
+func ScoreByProfit(_ *Bundle, res *SimulateResponse) (*big.Int, error) {
+	return parseBigInt(res.Profit)
+}
+
+// ScoreByMevGasPrice scores a simulation by its effective gas price, the profit per unit of gas.
+func ScoreByMevGasPrice(_ *Bundle, res *SimulateResponse) (*big.Int, error) {
+	return parseBigInt(res.MevGasPrice)
+}
+
+// CandidateResult is the simulation of one candidate bundle.
+type CandidateResult struct {
+	// Index is the position of the bundle in the candidates.
+	Index  int
+	Bundle *Bundle
+	// Response is nil when the candidate was not simulated.
+	Response *SimulateResponse
+	// Score is set for successful simulations only.
+	Score *big.Int
+	// Err is the simulation or scoring error, ErrSimulationDeadline when the deadline cut the candidate off.
+	Err error
+}
+
+// simulateManyConfig holds the settings of a single SimulateMany call.
+type simulateManyConfig struct {
+	workers        int
+	limiter        *rate.Limiter
+	scorer         SimulationScorer
+	deadline       time.Time
+	deadlineMargin time.Duration
+	bundleOpts     []BundleOption
+}
+
+// SimulateManyOption configures SimulateMany.
+type SimulateManyOption func(*simulateManyConfig) error
+
+// WithSimulateWorkers sets how many simulations run at the same time. Defaults to 8.
+func WithSimulateWorkers(n int) SimulateManyOption {
+	return func(cfg *simulateManyConfig) error {
+		if n <= 0 {
+			return fmt.Errorf("number of workers must be positive")
+		}
+		cfg.workers = n
+		return nil
+	}
+}
+
+// WithSimulateRateLimit makes every simulation wait for limiter first. Share one limiter between calls
+// to stay within the relay's rate limit.
+func WithSimulateRateLimit(limiter *rate.Limiter) SimulateManyOption {
+	return func(cfg *simulateManyConfig) error {
+		cfg.limiter = limiter
+		return nil
+	}
+}
+
+// WithSimulateScorer sets how successful simulations are ranked. Defaults to ScoreByProfit.
+func WithSimulateScorer(scorer SimulationScorer) SimulateManyOption {
+	return func(cfg *simulateManyConfig) error {
+		if scorer == nil {
+			return fmt.Errorf("scorer cannot be nil")
+		}
+		cfg.scorer = scorer
+		return nil
+	}
+}
+
+// WithSimulateDeadline sets when the remaining work stops. By default it is the target block's expected
+// timestamp minus the deadline margin, when an Ethereum client is configured.
+func WithSimulateDeadline(deadline time.Time) SimulateManyOption {
+	return func(cfg *simulateManyConfig) error {
+		cfg.deadline = deadline
+		return nil
+	}
+}
+
+// WithSimulateDeadlineMargin sets how long before the target block's timestamp the default deadline falls.
+// Defaults to 1 second.
+func WithSimulateDeadlineMargin(margin time.Duration) SimulateManyOption {
+	return func(cfg *simulateManyConfig) error {
+		cfg.deadlineMargin = margin
+		return nil
+	}
+}
+
+// WithSimulateBundleOptions sets the options every candidate is simulated with.
+func WithSimulateBundleOptions(opts ...BundleOption) SimulateManyOption {
+	return func(cfg *simulateManyConfig) error {
+		cfg.bundleOpts = opts
+		return nil
+	}
+}
+
+// SimulateMany simulates candidate bundles for the same block on a bounded worker pool and returns one
+// result per candidate, ranked: successful simulations first by descending score, then the others in
+// candidate order. Candidates still queued or running at the deadline get ErrSimulationDeadline.
+func (f *flashbot) SimulateMany(ctx context.Context, candidates []*Bundle, targetBlock uint64, opts ...SimulateManyOption) ([]*CandidateResult, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.SimulateMany")
+	defer span.End()
+
+	if len(candidates) == 0 {
+		span.SetStatus(codes.Error, "no candidates")
+		return nil, fmt.Errorf("candidates cannot be empty")
+	}
+	cfg := simulateManyConfig{
+		workers:        defaultSimulateWorkers,
+		scorer:         ScoreByProfit,
+		deadlineMargin: defaultSimulateDeadlineMargin,
+	}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+
+	deadline := cfg.deadline
+	if deadline.IsZero() && f.ethC != nil && targetBlock != 0 {
+		header, err := f.ethC.HeaderByNumber(ctx, nil)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to get latest header: %w", err)
+		}
+		// A target at or below the head has been built already, its deadline passed.
+		slot := int64(header.Time)
+		if head := header.Number.Uint64(); targetBlock > head {
+			slot += int64(targetBlock-head) * secondsPerSlot
+		}
+		deadline = time.Unix(slot, 0).Add(-cfg.deadlineMargin)
+	}
+	workCtx, cancel := ctx, context.CancelFunc(func() {})
+	if !deadline.IsZero() {
+		workCtx, cancel = context.WithDeadline(ctx, deadline)
+	}
+	defer cancel()
+
+	results := make([]*CandidateResult, len(candidates))
+	for i, bundle := range candidates {
+		results[i] = &CandidateResult{Index: i, Bundle: bundle}
+	}
+	// stopped returns the error of candidates the work context cut off.
+	stopped := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return ErrSimulationDeadline
+	}
+
+	jobs := make(chan *CandidateResult)
+	var wg sync.WaitGroup
+	for w := 0; w < min(cfg.workers, len(candidates)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				if cfg.limiter != nil {
+					if err := cfg.limiter.Wait(workCtx); err != nil {
+						r.Err = stopped()
+						continue
+					}
+				}
+				res, err := f.Simulate(workCtx, r.Bundle, targetBlock, cfg.bundleOpts...)
+				r.Response = res
+				switch {
+				case workCtx.Err() != nil && err != nil:
+					r.Err = stopped()
+				case err != nil:
+					r.Err = err
+				case res.Success:
+					r.Score, r.Err = cfg.scorer(r.Bundle, res)
+				default:
+					r.Err = simulationFailure(res)
+				}
+			}
+		}()
+	}
+feed:
+	for i, r := range results {
+		select {
+		case jobs <- r:
+		case <-workCtx.Done():
+			for _, skipped := range results[i:] {
+				skipped.Err = stopped()
+			}
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		aRanked, bRanked := a.Err == nil && a.Score != nil, b.Err == nil && b.Score != nil
+		if aRanked != bRanked {
+			return aRanked
+		}
+		if aRanked {
+			return a.Score.Cmp(b.Score) > 0
+		}
+		return false
+	})
+	span.SetStatus(codes.Ok, "candidates simulated")
+	return results, nil
+}
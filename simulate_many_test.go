@@ -0,0 +1,87 @@
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestSimulateMany(t *testing.T) {
+	ctx := context.Background()
+	relay := newTestRelay(t)
+	// The profit of a candidate is ten times the nonce of its transaction, nonce 2 fails.
+	relay.handle(methodMevSimBundle, func(params []json.RawMessage) (interface{}, *rpcError) {
+		var p mevSimBundleParams
+		if err := json.Unmarshal(params[0], &p); err != nil {
+			return nil, invalidParams(t, err)
+		}
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(hexutil.MustDecode(*p.Body[0].Tx)); err != nil {
+			return nil, invalidParams(t, err)
+		}
+		if tx.Nonce() == 2 {
+			return MevSimResponse{Success: false, Error: "insufficient funds"}, nil
+		}
+		return MevSimResponse{Success: true, Profit: hexutil.EncodeUint64(tx.Nonce() * 10)}, nil
+	})
+	fb, err := New(ctx, WithRelayURL(relay.URL))
+	require.NoError(t, err)
+
+	candidates := make([]*Bundle, 4)
+	for i := range candidates {
+		tx, _ := newTestTx(t, uint64(i))
+		candidates[i] = &Bundle{Transactions: []*types.Transaction{tx}}
+	}
+	indices := func(results []*CandidateResult) []int {
+		var out []int
+		for _, r := range results {
+			out = append(out, r.Index)
+		}
+		return out
+	}
+
+	t.Run("ranked by profit", func(t *testing.T) {
+		results, err := fb.SimulateMany(ctx, candidates, 101,
+			WithSimulateWorkers(2),
+			WithSimulateRateLimit(rate.NewLimiter(rate.Inf, 1)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, []int{3, 1, 0, 2}, indices(results))
+		require.Equal(t, big.NewInt(30), results[0].Score)
+		require.Same(t, candidates[3], results[0].Bundle)
+		require.False(t, results[3].Response.Success)
+		require.Nil(t, results[3].Score)
+		require.ErrorContains(t, results[3].Err, "insufficient funds")
+	})
+
+	t.Run("custom scorer", func(t *testing.T) {
+		lowest := func(bundle *Bundle, res *SimulateResponse) (*big.Int, error) {
+			profit, err := ScoreByProfit(bundle, res)
+			if err != nil {
+				return nil, err
+			}
+			return profit.Neg(profit), nil
+		}
+		results, err := fb.SimulateMany(ctx, candidates, 101, WithSimulateScorer(lowest))
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 3, 2}, indices(results))
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		calls := relay.callCount(methodMevSimBundle)
+		results, err := fb.SimulateMany(ctx, candidates, 101, WithSimulateDeadline(time.Now().Add(-time.Second)))
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 2, 3}, indices(results))
+		for _, r := range results {
+			require.ErrorIs(t, r.Err, ErrSimulationDeadline)
+		}
+		require.Equal(t, calls, relay.callCount(methodMevSimBundle))
+	})
+}
@@ -0,0 +1,436 @@
+package flashbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Simulator simulates bundles. The relay's mev_simBundle is used unless one is set with WithSimulator.
+type Simulator interface {
+	// Simulate executes the bundle as if it were included in targetBlock. 0 means the block after the latest one.
+	Simulate(ctx context.Context, bundle *Bundle, targetBlock uint64) (*SimulateResponse, error)
+}
+
+// OverridableSimulator is a Simulator that also applies the block overrides of WithSimOptions. Simulate
+// fails when overrides are given and the simulator set with WithSimulator does not implement it.
+type OverridableSimulator interface {
+	Simulator
+	// SimulateWithOverrides is Simulate on the block described by overrides; nil overrides nothing.
+	SimulateWithOverrides(ctx context.Context, bundle *Bundle, targetBlock uint64, overrides *MevSimBundleOptions) (*SimulateResponse, error)
+}
+
+// LocalSimulator executes bundles with go-ethereum's EVM on top of the state of the block before the target
+// block, fetched lazily from an Ethereum node. It needs no relay, only a node serving historical state for
+// the blocks simulated against (a full node for recent blocks, an archive node for older ones).
+//
+// A past block is simulated with its own header, so profit is measured against the builder that built it.
+// A future block inherits the gas limit, coinbase and prevrandao of its parent, and its base fee and blob
+// base fee are those of the block right after the parent, even when the target is several blocks ahead:
+// set them with WithSimBaseFee to simulate further blocks at another base fee. Its timestamp follows from
+// the slot time. Beacon root and history system calls are not applied.
+type LocalSimulator struct {
+	ethC        *ethclient.Client
+	tracer      trace.Tracer
+	chainConfig *params.ChainConfig
+	coinbase    *common.Address
+	// skipNonceChecks runs transactions whatever the nonce of their sender.
+	skipNonceChecks bool
+}
+
+var _ OverridableSimulator = (*LocalSimulator)(nil)
+
+// LocalSimulatorOption configures a LocalSimulator.
+type LocalSimulatorOption func(*LocalSimulator) error
+
+// WithLocalChainConfig sets the chain rules to execute with. By default they are looked up from the node's
+// chain ID, which is only possible for mainnet, Sepolia, Holesky and Hoodi.
+func WithLocalChainConfig(cfg *params.ChainConfig) LocalSimulatorOption {
+	return func(s *LocalSimulator) error {
+		if cfg == nil {
+			return fmt.Errorf("chain config cannot be nil")
+		}
+		s.chainConfig = cfg
+		return nil
+	}
+}
+
+// WithLocalCoinbase sets the fee recipient of the simulated block. Defaults to the block's own for past
+// blocks, and to the parent block's for future ones.
+func WithLocalCoinbase(coinbase common.Address) LocalSimulatorOption {
+	return func(s *LocalSimulator) error {
+		s.coinbase = &coinbase
+		return nil
+	}
+}
+
+// WithLocalSkipNonceChecks runs transactions whatever the nonce of their sender, so bundles signed for one
+// block can be replayed on the state of others.
+func WithLocalSkipNonceChecks() LocalSimulatorOption {
+	return func(s *LocalSimulator) error {
+		s.skipNonceChecks = true
+		return nil
+	}
+}
+
+// NewLocalSimulator returns a simulator reading state from ethC.
+func NewLocalSimulator(ethC *ethclient.Client, opts ...LocalSimulatorOption) (*LocalSimulator, error) {
+	if ethC == nil {
+		return nil, ErrEthClientNotConfigured
+	}
+	s := &LocalSimulator{
+		ethC:   ethC,
+		tracer: otel.GetTracerProvider().Tracer("flashbot"),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Simulate executes the bundle's transactions in order. The response has the shape of mev_simBundle's:
+// Profit is the coinbase balance difference and MevGasPrice the profit per unit of gas. Logs hold one entry
+// per executed transaction. A transaction that is invalid, or reverts without being allowed to, stops the
+// simulation with Success false and Error set; only failures to reach the node are returned as errors.
+func (s *LocalSimulator) Simulate(ctx context.Context, bundle *Bundle, targetBlock uint64) (*SimulateResponse, error) {
+	return s.SimulateWithOverrides(ctx, bundle, targetBlock, nil)
+}
+
+// SimulateWithOverrides is Simulate on the block described by overrides: ParentBlock selects the state,
+// BlockNumber the number of the simulated block, and its other fields replace those of the block's header.
+// Timeout bounds the whole simulation.
+func (s *LocalSimulator) SimulateWithOverrides(ctx context.Context, bundle *Bundle, targetBlock uint64, overrides *MevSimBundleOptions) (*SimulateResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "flashbot.LocalSimulator.Simulate")
+	defer span.End()
+
+	if overrides == nil {
+		overrides = &MevSimBundleOptions{}
+	}
+	if overrides.Timeout != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*overrides.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	if len(bundle.Transactions) == 0 {
+		span.SetStatus(codes.Error, "bundle is empty")
+		return nil, fmt.Errorf("bundle cannot be empty")
+	}
+	config, err := s.config(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	head, err := s.ethC.BlockNumber(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+	if overrides.BlockNumber != nil {
+		targetBlock = overrides.BlockNumber.ToInt().Uint64()
+	}
+	stateBlock, err := parentBlock(overrides.ParentBlock, head)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	switch {
+	case targetBlock == 0 && overrides.ParentBlock != nil:
+		targetBlock = stateBlock + 1
+	case targetBlock == 0:
+		targetBlock = head + 1
+	case overrides.ParentBlock == nil:
+		stateBlock = min(targetBlock-1, head)
+	}
+	if targetBlock <= stateBlock {
+		err := fmt.Errorf("block %d is not after its parent block %d", targetBlock, stateBlock)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	parent, err := s.ethC.HeaderByNumber(ctx, new(big.Int).SetUint64(stateBlock))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get header of block %d: %w", stateBlock, err)
+	}
+
+	header, err := s.blockHeader(ctx, config, parent, targetBlock, head)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	overrideHeader(header, overrides)
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     s.getHashFn(ctx, parent),
+		Coinbase:    header.Coinbase,
+		GasLimit:    header.GasLimit,
+		BlockNumber: header.Number,
+		Time:        header.Time,
+		Difficulty:  header.Difficulty,
+		BaseFee:     header.BaseFee,
+		Random:      &header.MixDigest,
+	}
+	if header.ExcessBlobGas != nil {
+		blockCtx.BlobBaseFee = eip4844.CalcBlobFee(config, header)
+	}
+
+	reader := newRemoteStateReader(ctx, s.ethC, parent.Number)
+	db := state.NewDatabase(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil), nil)
+	statedb, err := state.NewWithReader(parent.Root, db, reader)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to open state: %w", err)
+	}
+
+	result, err := executeBundle(bundle, config, blockCtx, header, statedb, s.skipNonceChecks)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	result.StateBlock = hexutil.EncodeUint64(stateBlock)
+	span.SetStatus(codes.Ok, "local simulation completed")
+	return result, nil
+}
+
+// executeBundle applies the bundle's transactions to statedb. It returns an error only when the state could
+// not be read, transaction failures are reported in the response.
+func executeBundle(bundle *Bundle, config *params.ChainConfig, blockCtx vm.BlockContext, header *types.Header, statedb *state.StateDB, skipNonceChecks bool) (*SimulateResponse, error) {
+	signer := types.MakeSigner(config, header.Number, header.Time)
+	gasPool := new(core.GasPool).AddGas(header.GasLimit)
+	evm := vm.NewEVM(blockCtx, statedb, config, vm.Config{})
+	coinbaseBefore := statedb.GetBalance(header.Coinbase).ToBig()
+
+	resp := &SimulateResponse{Success: true}
+	var gasUsed uint64
+	for i, tx := range bundle.Transactions {
+		txResult := TxLogResult{TxHash: tx.Hash().Hex()}
+		failure := func(reason string) {
+			resp.Success = false
+			resp.Error = fmt.Sprintf("transaction %d (%s): %s", i, tx.Hash().Hex(), reason)
+			resp.ExecError = reason
+			resp.Revert = txResult.Revert
+			txResult.Error = reason
+		}
+
+		msg, err := core.TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			failure(err.Error())
+			resp.Logs = append(resp.Logs, txResult)
+			break
+		}
+		msg.SkipNonceChecks = skipNonceChecks
+		statedb.SetTxContext(tx.Hash(), i)
+		snapshot := statedb.Snapshot()
+		evm.SetTxContext(core.NewEVMTxContext(msg))
+		result, err := core.ApplyMessage(evm, msg, gasPool)
+		if dbErr := statedb.Error(); dbErr != nil {
+			return nil, dbErr
+		}
+		if err != nil {
+			statedb.RevertToSnapshot(snapshot)
+			failure(err.Error())
+			resp.Logs = append(resp.Logs, txResult)
+			break
+		}
+		statedb.Finalise(true)
+
+		gasUsed += result.UsedGas
+		txResult.GasUsed = hexutil.EncodeUint64(result.UsedGas)
+		if result.Failed() {
+			txResult.Error = result.Err.Error()
+			if revert := result.Revert(); len(revert) > 0 {
+				txResult.Revert = hexutil.Encode(revert)
+			}
+			if !bundle.canRevert(i) {
+				failure(result.Err.Error())
+				resp.Logs = append(resp.Logs, txResult)
+				break
+			}
+		}
+		for _, log := range statedb.GetLogs(tx.Hash(), header.Number.Uint64(), common.Hash{}, header.Time) {
+			txResult.TxLogs = append(txResult.TxLogs, newLogEntry(log))
+		}
+		resp.Logs = append(resp.Logs, txResult)
+	}
+
+	profit := new(big.Int).Sub(statedb.GetBalance(header.Coinbase).ToBig(), coinbaseBefore)
+	mevGasPrice := new(big.Int)
+	if gasUsed > 0 {
+		mevGasPrice.Div(profit, new(big.Int).SetUint64(gasUsed))
+	}
+	resp.GasUsed = hexutil.EncodeUint64(gasUsed)
+	resp.Profit = hexutil.EncodeBig(profit)
+	// Only the profit of refundable (backrun) transactions is refundable, which a plain bundle has none of.
+	resp.RefundableValue = hexutil.EncodeBig(new(big.Int))
+	resp.MevGasPrice = hexutil.EncodeBig(mevGasPrice)
+	return resp, nil
+}
+
+// blockHeader returns the header of targetBlock as far as the EVM needs it. A block the node already has,
+// built on parent, keeps its own coinbase, timestamp, base fee and prevrandao; a future one is derived from
+// parent.
+func (s *LocalSimulator) blockHeader(ctx context.Context, config *params.ChainConfig, parent *types.Header, targetBlock, head uint64) (*types.Header, error) {
+	if targetBlock > head || targetBlock != parent.Number.Uint64()+1 {
+		return s.nextHeader(config, parent, targetBlock), nil
+	}
+	block, err := s.ethC.HeaderByNumber(ctx, new(big.Int).SetUint64(targetBlock))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header of block %d: %w", targetBlock, err)
+	}
+	header := &types.Header{
+		ParentHash:    block.ParentHash,
+		Coinbase:      block.Coinbase,
+		Number:        new(big.Int).Set(block.Number),
+		GasLimit:      block.GasLimit,
+		Time:          block.Time,
+		Difficulty:    new(big.Int).Set(block.Difficulty),
+		MixDigest:     block.MixDigest,
+		BaseFee:       block.BaseFee,
+		ExcessBlobGas: block.ExcessBlobGas,
+	}
+	if s.coinbase != nil {
+		header.Coinbase = *s.coinbase
+	}
+	return header, nil
+}
+
+// nextHeader derives the header of targetBlock from its parent, as far as the EVM needs it. The timestamp is
+// extrapolated to targetBlock, the base fee and excess blob gas are the parent's next block's.
+func (s *LocalSimulator) nextHeader(config *params.ChainConfig, parent *types.Header, targetBlock uint64) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase,
+		Number:     new(big.Int).SetUint64(targetBlock),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + (targetBlock-parent.Number.Uint64())*secondsPerSlot,
+		Difficulty: new(big.Int),
+		MixDigest:  parent.MixDigest,
+	}
+	if s.coinbase != nil {
+		header.Coinbase = *s.coinbase
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+	if config.IsCancun(header.Number, header.Time) && parent.ExcessBlobGas != nil {
+		excess := eip4844.CalcExcessBlobGas(config, parent, header.Time)
+		header.ExcessBlobGas = &excess
+	}
+	return header
+}
+
+// overrideHeader replaces the fields of header that overrides set.
+func overrideHeader(header *types.Header, overrides *MevSimBundleOptions) {
+	if overrides.Coinbase != nil {
+		header.Coinbase = *overrides.Coinbase
+	}
+	if overrides.Timestamp != nil {
+		header.Time = uint64(*overrides.Timestamp)
+	}
+	if overrides.GasLimit != nil {
+		header.GasLimit = uint64(*overrides.GasLimit)
+	}
+	if overrides.BaseFee != nil {
+		header.BaseFee = new(big.Int).Set(overrides.BaseFee.ToInt())
+	}
+}
+
+// parentBlock resolves the ParentBlock override, a hex number or "latest", to a block number. Without the
+// override it returns head.
+func parentBlock(block *string, head uint64) (uint64, error) {
+	if block == nil || *block == "latest" {
+		return head, nil
+	}
+	number, err := hexutil.DecodeUint64(*block)
+	if err != nil {
+		return 0, fmt.Errorf("invalid parent block %q: %w", *block, err)
+	}
+	if number > head {
+		return 0, fmt.Errorf("parent block %d is past the latest block %d", number, head)
+	}
+	return number, nil
+}
+
+// getHashFn returns the BLOCKHASH lookup of the simulated block, fetching ancestors from the node on demand.
+func (s *LocalSimulator) getHashFn(ctx context.Context, parent *types.Header) vm.GetHashFunc {
+	var mu sync.Mutex
+	hashes := map[uint64]common.Hash{parent.Number.Uint64(): parent.Hash()}
+	return func(n uint64) common.Hash {
+		mu.Lock()
+		defer mu.Unlock()
+		if hash, ok := hashes[n]; ok {
+			return hash
+		}
+		header, err := s.ethC.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return common.Hash{}
+		}
+		hashes[n] = header.Hash()
+		return hashes[n]
+	}
+}
+
+// config returns the chain rules, looking them up from the node's chain ID the first time.
+func (s *LocalSimulator) config(ctx context.Context) (*params.ChainConfig, error) {
+	if s.chainConfig != nil {
+		return s.chainConfig, nil
+	}
+	chainID, err := s.ethC.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	for _, cfg := range []*params.ChainConfig{params.MainnetChainConfig, params.SepoliaChainConfig, params.HoleskyChainConfig, params.HoodiChainConfig} {
+		if cfg.ChainID.Cmp(chainID) == 0 {
+			s.chainConfig = cfg
+			return cfg, nil
+		}
+	}
+	return nil, errors.New("unknown chain, set its rules with WithLocalChainConfig")
+}
+
+// canRevert reports whether the transaction at index i is allowed to revert.
+func (b *Bundle) canRevert(i int) bool {
+	return i < len(b.CanRevert) && b.CanRevert[i]
+}
+
+func newLogEntry(log *types.Log) LogEntry {
+	topics := make([]string, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = topic.Hex()
+	}
+	return LogEntry{
+		Address: log.Address.Hex(),
+		Topics:  topics,
+		Data:    hexutil.Encode(log.Data),
+	}
+}
@@ -0,0 +1,142 @@
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalSimulator(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	node.setHead(100)
+
+	// logger emits its first 32 bytes of memory as LOG0, reverter reverts with the word 0x2a.
+	logger := common.HexToAddress("0x00000000000000000000000000000000000010c0")
+	reverter := common.HexToAddress("0x000000000000000000000000000000000000dead")
+	node.code[logger] = common.FromHex("0x60206000a000")
+	node.code[reverter] = common.FromHex("0x602a60005260206000fd")
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	node.balances[sender] = big.NewInt(1e18)
+	sign := func(nonce uint64, to common.Address, value int64) *types.Transaction {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(SepoliaChainID)), &types.DynamicFeeTx{
+			ChainID:   big.NewInt(SepoliaChainID),
+			Nonce:     nonce,
+			To:        &to,
+			Value:     big.NewInt(value),
+			Gas:       100_000,
+			GasFeeCap: big.NewInt(3e9),
+			GasTipCap: big.NewInt(1e9),
+		})
+		require.NoError(t, err)
+		return tx
+	}
+
+	sim, err := NewLocalSimulator(ethC)
+	require.NoError(t, err)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC), WithSimulator(sim))
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		bundle := &Bundle{
+			Transactions: []*types.Transaction{sign(0, logger, 0), sign(1, reverter, 0), sign(2, testCoinbase, 1000)},
+			CanRevert:    []bool{false, true, false},
+		}
+		res, err := fb.Simulate(ctx, bundle, 101)
+		require.NoError(t, err)
+		require.True(t, res.Success, res.Error)
+		require.Equal(t, "0x64", res.StateBlock)
+		require.Len(t, res.Logs, 3)
+
+		require.Len(t, res.Logs[0].TxLogs, 1)
+		require.Equal(t, logger.Hex(), res.Logs[0].TxLogs[0].Address)
+		require.Equal(t, "execution reverted", res.Logs[1].Error)
+		require.Equal(t, common.BigToHash(big.NewInt(42)).Hex(), res.Logs[1].Revert)
+
+		gasUsed, err := parseBigInt(res.GasUsed)
+		require.NoError(t, err)
+		var perTx uint64
+		for _, l := range res.Logs {
+			g, err := parseBigInt(l.GasUsed)
+			require.NoError(t, err)
+			perTx += g.Uint64()
+		}
+		require.Equal(t, gasUsed.Uint64(), perTx)
+
+		// The builder earns the 1 gwei tip on every unit of gas plus the direct payment.
+		profit, err := parseBigInt(res.Profit)
+		require.NoError(t, err)
+		expected := new(big.Int).Mul(gasUsed, big.NewInt(1e9))
+		require.Equal(t, expected.Add(expected, big.NewInt(1000)), profit)
+		require.Equal(t, "0x0", res.RefundableValue)
+	})
+
+	t.Run("revert not allowed", func(t *testing.T) {
+		res, err := fb.Simulate(ctx, &Bundle{Transactions: []*types.Transaction{sign(0, reverter, 0), sign(1, logger, 0)}}, 101)
+		var revertErr *SimulationRevertError
+		require.ErrorAs(t, err, &revertErr)
+		require.Equal(t, 0, revertErr.TxIndex)
+		require.False(t, res.Success)
+		require.Contains(t, res.Error, "transaction 0")
+		require.Len(t, res.Logs, 1)
+	})
+
+	t.Run("invalid nonce", func(t *testing.T) {
+		res, err := fb.Simulate(ctx, &Bundle{Transactions: []*types.Transaction{sign(5, logger, 0)}}, 101)
+		var invalidErr *SimulationInvalidError
+		require.ErrorAs(t, err, &invalidErr)
+		require.Equal(t, 0, invalidErr.TxIndex)
+		require.False(t, res.Success)
+		require.Contains(t, res.Error, "nonce too high")
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		bundle := &Bundle{Transactions: []*types.Transaction{sign(0, logger, 0), sign(1, testCoinbase, 1000)}}
+		res, err := fb.Simulate(ctx, bundle, 101, WithSimOptions(
+			WithSimParentBlock(99),
+			WithSimCoinbase(common.Address{0xc0}),
+			WithSimBaseFee(big.NewInt(2.5e9)),
+		))
+		require.NoError(t, err)
+		require.True(t, res.Success, res.Error)
+		require.Equal(t, "0x63", res.StateBlock)
+
+		// The payment goes to the real coinbase, the overridden one only earns the tip left over the base fee.
+		gasUsed, err := parseBigInt(res.GasUsed)
+		require.NoError(t, err)
+		profit, err := parseBigInt(res.Profit)
+		require.NoError(t, err)
+		require.Equal(t, new(big.Int).Mul(gasUsed, big.NewInt(0.5e9)), profit)
+
+		_, err = fb.Simulate(ctx, bundle, 101, WithSimOptions(WithSimTimeout(time.Millisecond)))
+		require.ErrorContains(t, err, "timeout must be at least one second")
+		_, err = fb.Simulate(ctx, bundle, 101, WithSimOptions(WithSimParentBlock(101)))
+		require.ErrorContains(t, err, "past the latest block")
+	})
+
+	t.Run("overrides unsupported", func(t *testing.T) {
+		custom, err := New(ctx, WithChainID(SepoliaChainID), WithSimulator(plainSimulator{sim}))
+		require.NoError(t, err)
+		bundle := &Bundle{Transactions: []*types.Transaction{sign(0, logger, 0)}}
+		_, err = custom.Simulate(ctx, bundle, 101)
+		require.NoError(t, err)
+		_, err = custom.Simulate(ctx, bundle, 101, WithSimOptions(WithSimCoinbase(common.Address{0xc0})))
+		require.ErrorIs(t, err, ErrSimOverridesUnsupported)
+	})
+}
+
+// plainSimulator hides the overrides support of the simulator it wraps.
+type plainSimulator struct{ sim Simulator }
+
+func (p plainSimulator) Simulate(ctx context.Context, bundle *Bundle, targetBlock uint64) (*SimulateResponse, error) {
+	return p.sim.Simulate(ctx, bundle, targetBlock)
+}
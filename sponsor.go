@@ -0,0 +1,149 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// SponsorBundle builds a bundle in which sponsorSigner funds every user that cannot pay for their own transactions.
+// The ETH a user needs is the worst-case cost of all their transactions (gas limit * fee cap + value),
+// minus their current balance. One transfer per underfunded user is signed with consecutive sponsor nonces
+// and placed before the user transactions, which keep their original order. Sponsor nonces are reserved from
+// the client's nonce manager.
+// Users whose account is delegated (EIP-7702) run code on receipt, so their transfer gas is estimated.
+func (f *flashbot) SponsorBundle(ctx context.Context, userTxs []*types.Transaction, sponsorSigner TxSigner) (*Bundle, error) {
+	ctx, span := f.tracer.Start(ctx, "flashbot.SponsorBundle")
+	defer span.End()
+
+	if f.ethC == nil {
+		span.SetStatus(codes.Error, ErrEthClientNotConfigured.Error())
+		return nil, ErrEthClientNotConfigured
+	}
+	if len(userTxs) == 0 {
+		span.SetStatus(codes.Error, "no user transactions")
+		return nil, fmt.Errorf("user transactions cannot be empty")
+	}
+	chainID, err := f.getChainID(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Sum the worst-case cost per sender, keeping the order in which senders first appear.
+	signer := types.LatestSignerForChainID(chainID)
+	sponsor := sponsorSigner.Address()
+	var users []common.Address
+	required := make(map[common.Address]*big.Int)
+	for i, tx := range userTxs {
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+		}
+		if sender == sponsor {
+			span.SetStatus(codes.Error, "sponsor sends a user transaction")
+			return nil, fmt.Errorf("transaction %d is sent by the sponsor, its nonce would collide with the funding transfers", i)
+		}
+		if _, ok := required[sender]; !ok {
+			users = append(users, sender)
+			required[sender] = new(big.Int)
+		}
+		required[sender].Add(required[sender], tx.Cost())
+	}
+	// An authorization signed by the sponsor consumes a sponsor nonce the funding transfers already use.
+	if _, ok := (&Bundle{Transactions: userTxs}).authorities()[sponsor]; ok {
+		span.SetStatus(codes.Error, "sponsor signs a user authorization")
+		return nil, fmt.Errorf("the sponsor signs a set-code authorization, its nonce would collide with the funding transfers")
+	}
+
+	gasPrice, tip, err := f.GetGasPrice(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	// Collect the transfers first, so the sponsor reserves exactly as many nonces as it signs.
+	var transfers []*types.DynamicFeeTx
+	for _, user := range users {
+		balance, err := f.ethC.BalanceAt(ctx, user, nil)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to get balance of %s: %w", user.Hex(), err)
+		}
+		shortfall := new(big.Int).Sub(required[user], balance)
+		if shortfall.Sign() <= 0 {
+			continue
+		}
+
+		to := user
+		gas, err := f.transferGas(ctx, sponsor, to, shortfall)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
+		transfers = append(transfers, &types.DynamicFeeTx{
+			ChainID:   chainID,
+			To:        &to,
+			Value:     shortfall,
+			Gas:       gas,
+			GasFeeCap: gasPrice,
+			GasTipCap: tip,
+		})
+	}
+
+	bundle := &Bundle{
+		Transactions: make([]*types.Transaction, 0, len(transfers)+len(userTxs)),
+	}
+	if len(transfers) > 0 {
+		nonce, err := f.nonces.Reserve(ctx, sponsor, len(transfers))
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to reserve sponsor nonces: %w", err)
+		}
+		for i, transfer := range transfers {
+			transfer.Nonce = nonce + uint64(i)
+			tx, err := sponsorSigner.SignTx(ctx, types.NewTx(transfer), chainID)
+			if err != nil {
+				f.nonces.Release(sponsor, reservedRange(nonce, len(transfers))...)
+				span.SetStatus(codes.Error, err.Error())
+				span.RecordError(err)
+				return nil, fmt.Errorf("failed to sign funding transaction for %s: %w", transfer.To.Hex(), err)
+			}
+			bundle.Transactions = append(bundle.Transactions, tx)
+		}
+	}
+	bundle.Transactions = append(bundle.Transactions, userTxs...)
+	bundle.CanRevert = make([]bool, len(bundle.Transactions))
+
+	span.SetStatus(codes.Ok, "bundle sponsored successfully")
+	return bundle, nil
+}
+
+// transferGas returns the gas limit of a plain ETH transfer to to. Accounts without code take params.TxGas,
+// delegated accounts execute their delegate's code and are estimated by the node.
+func (f *flashbot) transferGas(ctx context.Context, from, to common.Address, value *big.Int) (uint64, error) {
+	code, err := f.ethC.CodeAt(ctx, to, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get code of %s: %w", to.Hex(), err)
+	}
+	if len(code) == 0 {
+		return params.TxGas, nil
+	}
+	gas, err := f.ethC.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Value: value})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate transfer gas to %s: %w", to.Hex(), err)
+	}
+	return gas, nil
+}
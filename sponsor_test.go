@@ -0,0 +1,48 @@
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSponsorBundle(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC))
+	require.NoError(t, err)
+
+	sponsorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sponsor := crypto.PubkeyToAddress(sponsorKey.PublicKey)
+	node.nonces[sponsor] = 9
+
+	poorTx, poor := newTestTx(t, 0)
+	richTx, rich := newTestTx(t, 0)
+	node.balances[poor] = big.NewInt(1000)
+	node.balances[rich] = new(big.Int).Mul(richTx.Cost(), big.NewInt(2))
+
+	bundle, err := fb.SponsorBundle(ctx, []*types.Transaction{poorTx, richTx}, NewPrivateKeySigner(sponsorKey))
+	require.NoError(t, err)
+	require.Len(t, bundle.Transactions, 3)
+	require.Len(t, bundle.CanRevert, 3)
+	require.Equal(t, poorTx.Hash(), bundle.Transactions[1].Hash())
+	require.Equal(t, richTx.Hash(), bundle.Transactions[2].Hash())
+
+	funding := bundle.Transactions[0]
+	require.Equal(t, poor, *funding.To())
+	require.Equal(t, uint64(9), funding.Nonce())
+	require.Equal(t, params.TxGas, funding.Gas())
+	require.Equal(t, new(big.Int).Sub(poorTx.Cost(), big.NewInt(1000)), funding.Value())
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(SepoliaChainID)), funding)
+	require.NoError(t, err)
+	require.Equal(t, sponsor, sender)
+
+	_, err = fb.SponsorBundle(ctx, []*types.Transaction{funding}, NewPrivateKeySigner(sponsorKey))
+	require.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package flashbot
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// testNode is a minimal in-process "eth" namespace used to drive the client without a real node.
+type testNode struct {
+	mu     sync.Mutex
+	head   uint64
+	nonces map[common.Address]uint64
+	// pending overrides nonces for the pending block, for senders with transactions in the mempool.
+	pending  map[common.Address]uint64
+	balances map[common.Address]*big.Int
+	code     map[common.Address][]byte
+	receipts map[common.Hash]*types.Receipt
+	// forks replaces the block at a height with a sibling of a different hash.
+	forks map[uint64][]byte
+	// coinbases replaces testCoinbase as the fee recipient of a block.
+	coinbases map[uint64]common.Address
+}
+
+func newTestNode(t *testing.T) (*testNode, *ethclient.Client) {
+	t.Helper()
+	n := &testNode{
+		nonces:    map[common.Address]uint64{},
+		pending:   map[common.Address]uint64{},
+		balances:  map[common.Address]*big.Int{},
+		code:      map[common.Address][]byte{},
+		receipts:  map[common.Hash]*types.Receipt{},
+		forks:     map[uint64][]byte{},
+		coinbases: map[uint64]common.Address{},
+	}
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("eth", n))
+	c := ethclient.NewClient(rpc.DialInProc(srv))
+	t.Cleanup(func() {
+		c.Close()
+		srv.Stop()
+	})
+	return n, c
+}
+
+func (n *testNode) setHead(head uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.head = head
+}
+
+func (n *testNode) include(tx *types.Transaction, block uint64, index uint) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.receipts[tx.Hash()] = &types.Receipt{
+		Type:              tx.Type(),
+		Status:            types.ReceiptStatusSuccessful,
+		Logs:              []*types.Log{},
+		TxHash:            tx.Hash(),
+		GasUsed:           tx.Gas(),
+		EffectiveGasPrice: big.NewInt(0),
+		BlockHash:         testBlockHash(block),
+		BlockNumber:       new(big.Int).SetUint64(block),
+		TransactionIndex:  index,
+	}
+}
+
+// reorg replaces the canonical block at height with a sibling.
+func (n *testNode) reorg(block uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.forks[block] = append(n.forks[block], 0x01)
+}
+
+func testBlockHash(block uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(block + 1000))
+}
+
+func (n *testNode) ChainId() hexutil.Uint64 {
+	return hexutil.Uint64(SepoliaChainID)
+}
+
+func (n *testNode) BlockNumber() hexutil.Uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return hexutil.Uint64(n.head)
+}
+
+func (n *testNode) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.receipts[hash]
+}
+
+func (n *testNode) GetTransactionCount(_ context.Context, addr common.Address, block rpc.BlockNumberOrHash) hexutil.Uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if number, ok := block.Number(); ok && number == rpc.PendingBlockNumber {
+		if nonce, ok := n.pending[addr]; ok {
+			return hexutil.Uint64(nonce)
+		}
+	}
+	return hexutil.Uint64(n.nonces[addr])
+}
+
+func (n *testNode) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(3e9))
+}
+
+func (n *testNode) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1e9))
+}
+
+// testBlobBaseFee is the blob base fee of the block after the test node's head.
+const testBlobBaseFee = 10
+
+func (n *testNode) BlobBaseFee() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(testBlobBaseFee))
+}
+
+func (n *testNode) GetBalance(_ context.Context, addr common.Address, _ rpc.BlockNumberOrHash) *hexutil.Big {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if b, ok := n.balances[addr]; ok {
+		return (*hexutil.Big)(b)
+	}
+	return (*hexutil.Big)(big.NewInt(0))
+}
+
+func (n *testNode) EstimateGas(_ context.Context, args map[string]interface{}, _ *rpc.BlockNumberOrHash) hexutil.Uint64 {
+	if data, ok := args["input"].(string); ok && len(data) > 2 {
+		return 50_000
+	}
+	return 21_000
+}
+
+// SimulateV1 runs the calls of each block in order. Calls with input 0x01 approve their sender and use 46000 gas,
+// calls with input 0x02 spend the sender's approval with 35000 gas and revert without one, others use 21000 gas.
+func (n *testNode) SimulateV1(_ context.Context, opts struct {
+	BlockStateCalls []struct {
+		Calls []struct {
+			From  common.Address `json:"from"`
+			Input hexutil.Bytes  `json:"input"`
+		} `json:"calls"`
+	} `json:"blockStateCalls"`
+}, _ rpc.BlockNumberOrHash) []map[string]interface{} {
+	approved := map[common.Address]bool{}
+	var blocks []map[string]interface{}
+	for _, block := range opts.BlockStateCalls {
+		var results []map[string]interface{}
+		for _, call := range block.Calls {
+			result := map[string]interface{}{"status": "0x1", "gasUsed": hexutil.Uint64(21_000)}
+			switch {
+			case bytes.Equal(call.Input, []byte{0x01}):
+				approved[call.From] = true
+				result["gasUsed"] = hexutil.Uint64(46_000)
+			case bytes.Equal(call.Input, []byte{0x02}) && approved[call.From]:
+				approved[call.From] = false
+				result["gasUsed"] = hexutil.Uint64(35_000)
+			case bytes.Equal(call.Input, []byte{0x02}):
+				result["status"] = "0x0"
+				result["gasUsed"] = hexutil.Uint64(23_000)
+				result["error"] = map[string]interface{}{"code": 3, "message": "execution reverted: insufficient allowance"}
+			}
+			results = append(results, result)
+		}
+		blocks = append(blocks, map[string]interface{}{"calls": results})
+	}
+	return blocks
+}
+
+func (n *testNode) GetCode(_ context.Context, addr common.Address, _ rpc.BlockNumberOrHash) hexutil.Bytes {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.code[addr]
+}
+
+// testCoinbase is the fee recipient of every test node block.
+var testCoinbase = common.HexToAddress("0x000000000000000000000000000000000000c014")
+
+func (n *testNode) GetBlockByNumber(_ context.Context, number rpc.BlockNumber, _ bool) *types.Header {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	block := n.head
+	if number >= 0 {
+		block = uint64(number)
+	}
+	if block > n.head {
+		return nil
+	}
+	excessBlobGas, blobGasUsed := uint64(0), uint64(0)
+	coinbase, ok := n.coinbases[block]
+	if !ok {
+		coinbase = testCoinbase
+	}
+	return &types.Header{
+		ParentHash:    testBlockHash(block - 1),
+		Coinbase:      coinbase,
+		Difficulty:    new(big.Int),
+		Number:        new(big.Int).SetUint64(block),
+		GasLimit:      30_000_000,
+		GasUsed:       15_000_000,
+		Time:          1_800_000_000 + block*12,
+		BaseFee:       big.NewInt(1e9),
+		ExcessBlobGas: &excessBlobGas,
+		BlobGasUsed:   &blobGasUsed,
+		Extra:         n.forks[block],
+	}
+}
+
+func (n *testNode) GetStorageAt(_ context.Context, addr common.Address, slot string, _ rpc.BlockNumberOrHash) hexutil.Bytes {
+	return common.Hash{}.Bytes()
+}
@@ -0,0 +1,76 @@
+package flashbot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testRelay is an httptest JSON-RPC server standing in for the Flashbots relay.
+type testRelay struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[method]func(params []json.RawMessage) (interface{}, *rpcError)
+	calls    map[method]int
+}
+
+func newTestRelay(t *testing.T) *testRelay {
+	t.Helper()
+	r := &testRelay{
+		handlers: map[method]func(params []json.RawMessage) (interface{}, *rpcError){},
+		calls:    map[method]int{},
+	}
+	// The server runs on its own goroutine, where only assert may report failures.
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.NotEmpty(t, req.Header.Get(headerFlashbotSignature))
+		var body struct {
+			Id     int               `json:"id"`
+			Method method            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); !assert.NoError(t, err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		r.mu.Lock()
+		r.calls[body.Method]++
+		handler, ok := r.handlers[body.Method]
+		r.mu.Unlock()
+
+		resp := map[string]interface{}{"jsonrpc": jsonRPCVersion, "id": body.Id}
+		if !ok {
+			resp["error"] = rpcError{Code: -32601, Message: "method not found"}
+		} else if result, rpcErr := handler(body.Params); rpcErr != nil {
+			resp["error"] = rpcErr
+		} else {
+			resp["result"] = result
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// invalidParams fails the test from a handler and answers the request with err.
+func invalidParams(t *testing.T, err error) *rpcError {
+	assert.NoError(t, err)
+	return &rpcError{Code: -32602, Message: err.Error()}
+}
+
+func (r *testRelay) handle(m method, handler func(params []json.RawMessage) (interface{}, *rpcError)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[m] = handler
+}
+
+func (r *testRelay) callCount(m method) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[m]
+}
@@ -0,0 +1,229 @@
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// TxIntent describes a transaction by what it should do. TxBuilder fills in everything else.
+type TxIntent struct {
+	// From signs the transaction.
+	From TxSigner
+	// To is the recipient, nil deploys Data as a contract.
+	To    *common.Address
+	Value *big.Int
+	Data  []byte
+	// AccessList is optional.
+	AccessList types.AccessList
+	// Gas skips estimation when set.
+	Gas uint64
+}
+
+// TxBuilder turns intents into signed EIP-1559 transactions ready for a Bundle.
+// The nonce is reserved from a nonce manager, the gas limit is estimated by the node plus a safety margin,
+// and the fees come from a gas strategy.
+type TxBuilder struct {
+	f            *flashbot
+	nonces       *NonceManager
+	strategy     GasStrategy
+	safetyMargin uint64
+}
+
+// TxBuilderOption configures a TxBuilder.
+type TxBuilderOption func(*TxBuilder) error
+
+// WithTxNonceManager sets the nonce manager nonces are reserved from. Defaults to the client's.
+func WithTxNonceManager(m *NonceManager) TxBuilderOption {
+	return func(b *TxBuilder) error {
+		if m == nil {
+			return fmt.Errorf("nonce manager cannot be nil")
+		}
+		b.nonces = m
+		return nil
+	}
+}
+
+// WithTxGasStrategy sets the strategy fees are priced with. Defaults to the client's (WithGasStrategy).
+func WithTxGasStrategy(strategy GasStrategy) TxBuilderOption {
+	return func(b *TxBuilder) error {
+		b.strategy = strategy
+		return nil
+	}
+}
+
+// WithTxGasMargin sets the percentage added to the estimated gas. Defaults to 25.
+func WithTxGasMargin(percent uint64) TxBuilderOption {
+	return func(b *TxBuilder) error {
+		b.safetyMargin = percent
+		return nil
+	}
+}
+
+// NewTxBuilder returns a builder signing transactions for the client's chain.
+func (f *flashbot) NewTxBuilder(opts ...TxBuilderOption) (*TxBuilder, error) {
+	if f.ethC == nil {
+		return nil, ErrEthClientNotConfigured
+	}
+	b := &TxBuilder{
+		f:            f,
+		nonces:       f.nonces,
+		safetyMargin: defaultGasSafetyMargin,
+	}
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Build fills in the chain ID, nonce, gas limit and fees of intent and signs it.
+// Gas is estimated against the latest state, so an intent that depends on an earlier transaction of the
+// same bundle (other than for its balance) should set Gas itself.
+// The reserved nonce is released when the transaction cannot be built.
+func (b *TxBuilder) Build(ctx context.Context, intent TxIntent) (*types.Transaction, error) {
+	return b.build(ctx, intent, nil)
+}
+
+// build builds intent with nonce, or with a nonce it reserves (and releases on failure) when nonce is nil.
+func (b *TxBuilder) build(ctx context.Context, intent TxIntent, nonce *uint64) (*types.Transaction, error) {
+	ctx, span := b.f.tracer.Start(ctx, "flashbot.TxBuilder.Build")
+	defer span.End()
+
+	if intent.From == nil {
+		span.SetStatus(codes.Error, "intent signer is nil")
+		return nil, fmt.Errorf("intent signer is required")
+	}
+	from := intent.From.Address()
+	value := intent.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	chainID, err := b.f.getChainID(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	var strategies []GasStrategy
+	if b.strategy != nil {
+		strategies = append(strategies, b.strategy)
+	}
+	feeCap, tip, err := b.f.GetGasPrice(ctx, strategies...)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	gas := intent.Gas
+	if gas == 0 {
+		// Fees are left out of the call so the sender does not need to hold the gas cost yet,
+		// it may be funded earlier in the bundle.
+		estimated, err := b.f.ethC.EstimateGas(ctx, ethereum.CallMsg{
+			From:       from,
+			To:         intent.To,
+			Value:      value,
+			Data:       intent.Data,
+			AccessList: intent.AccessList,
+		})
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		gas = AddGasMargin(estimated, b.safetyMargin)
+	}
+
+	reserved := nonce == nil
+	if reserved {
+		first, err := b.nonces.Reserve(ctx, from, 1)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to reserve nonce: %w", err)
+		}
+		nonce = &first
+	}
+	tx, err := intent.From.SignTx(ctx, types.NewTx(&types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      *nonce,
+		To:         intent.To,
+		Value:      value,
+		Gas:        gas,
+		GasFeeCap:  feeCap,
+		GasTipCap:  tip,
+		Data:       intent.Data,
+		AccessList: intent.AccessList,
+	}), chainID)
+	if err != nil {
+		if reserved {
+			b.nonces.Release(from, *nonce)
+		}
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	span.SetStatus(codes.Ok, "transaction built successfully")
+	return tx, nil
+}
+
+// BuildBundle builds every intent in order and returns them as a bundle in which no transaction can revert.
+// The nonces of each sender are reserved together before building, so the bundle gets consecutive nonces
+// even while other bundles of the same sender are built. On failure every reserved nonce is released.
+func (b *TxBuilder) BuildBundle(ctx context.Context, intents ...TxIntent) (*Bundle, error) {
+	counts := make(map[common.Address]int)
+	var senders []common.Address
+	for i, intent := range intents {
+		if intent.From == nil {
+			return nil, fmt.Errorf("failed to build transaction %d: intent signer is required", i)
+		}
+		from := intent.From.Address()
+		if counts[from] == 0 {
+			senders = append(senders, from)
+		}
+		counts[from]++
+	}
+	firsts := make(map[common.Address]uint64, len(senders))
+	release := func() {
+		for sender, first := range firsts {
+			b.nonces.Release(sender, reservedRange(first, counts[sender])...)
+		}
+	}
+	for _, sender := range senders {
+		first, err := b.nonces.Reserve(ctx, sender, counts[sender])
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("failed to reserve nonces of %s: %w", sender.Hex(), err)
+		}
+		firsts[sender] = first
+	}
+
+	next := make(map[common.Address]uint64, len(firsts))
+	for sender, first := range firsts {
+		next[sender] = first
+	}
+	bundle := &Bundle{
+		Transactions: make([]*types.Transaction, 0, len(intents)),
+		CanRevert:    make([]bool, len(intents)),
+	}
+	for i, intent := range intents {
+		from := intent.From.Address()
+		nonce := next[from]
+		next[from]++
+		tx, err := b.build(ctx, intent, &nonce)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("failed to build transaction %d: %w", i, err)
+		}
+		bundle.Transactions = append(bundle.Transactions, tx)
+	}
+	return bundle, nil
+}
@@ -0,0 +1,100 @@
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxBuilder(t *testing.T) {
+	ctx := context.Background()
+	node, ethC := newTestNode(t)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC))
+	require.NoError(t, err)
+	builder, err := fb.NewTxBuilder(WithTxGasMargin(10))
+	require.NoError(t, err)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewPrivateKeySigner(key)
+	node.nonces[signer.Address()] = 3
+	to := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	bundle, err := builder.BuildBundle(ctx,
+		TxIntent{From: signer, To: &to, Value: big.NewInt(1)},
+		TxIntent{From: signer, To: &to, Data: []byte{0xa9, 0x05, 0x9c, 0xbb}},
+		TxIntent{From: signer, To: &to, Gas: 30_000},
+	)
+	require.NoError(t, err)
+	require.Len(t, bundle.Transactions, 3)
+	require.Equal(t, []bool{false, false, false}, bundle.CanRevert)
+
+	for i, tx := range bundle.Transactions {
+		require.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+		require.Equal(t, uint64(3+i), tx.Nonce())
+		require.Equal(t, big.NewInt(SepoliaChainID), tx.ChainId())
+		require.Equal(t, big.NewInt(3e9), tx.GasFeeCap())
+		require.Equal(t, big.NewInt(1e9), tx.GasTipCap())
+		sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(SepoliaChainID)), tx)
+		require.NoError(t, err)
+		require.Equal(t, signer.Address(), sender)
+	}
+	require.Equal(t, uint64(23_100), bundle.Transactions[0].Gas())
+	require.Equal(t, uint64(55_000), bundle.Transactions[1].Gas())
+	require.Equal(t, uint64(30_000), bundle.Transactions[2].Gas())
+
+	_, err = builder.Build(ctx, TxIntent{To: &to})
+	require.Error(t, err)
+}
+
+func TestTxBuilderConcurrentBundles(t *testing.T) {
+	ctx := context.Background()
+	_, ethC := newTestNode(t)
+	fb, err := New(ctx, WithChainID(SepoliaChainID), WithEthClient(ethC))
+	require.NoError(t, err)
+	builder, err := fb.NewTxBuilder()
+	require.NoError(t, err)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewPrivateKeySigner(key)
+	to := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	intents := []TxIntent{
+		{From: signer, To: &to, Gas: 21_000},
+		{From: signer, To: &to, Gas: 21_000},
+		{From: signer, To: &to, Gas: 21_000},
+	}
+
+	const bundles = 8
+	var wg sync.WaitGroup
+	results := make([]*Bundle, bundles)
+	errs := make([]error, bundles)
+	for i := range bundles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = builder.BuildBundle(ctx, intents...)
+		}()
+	}
+	wg.Wait()
+
+	used := make(map[uint64]bool)
+	for i, bundle := range results {
+		require.NoError(t, errs[i])
+		// Every bundle gets consecutive nonces, never interleaved with another's.
+		first := bundle.Transactions[0].Nonce()
+		require.Zero(t, first%3)
+		for j, tx := range bundle.Transactions {
+			require.Equal(t, first+uint64(j), tx.Nonce())
+			require.False(t, used[tx.Nonce()])
+			used[tx.Nonce()] = true
+		}
+	}
+	require.Len(t, used, 3*bundles)
+}
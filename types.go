@@ -2,6 +2,7 @@ package flashbot
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 // method is a type that represents the method name of the RPC call.
@@ -52,14 +53,33 @@ type rpcError struct {
 	Message string `json:"message"`
 }
 
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s (code: %d)", e.Message, e.Code)
+}
+
 type callBundleResp struct {
-	BundleHash   string `json:"bundleHash"`
-	CoinbaseDiff string `json:"coinbaseDiff"` // Miner Profit
-	Results      []struct {
-		TxHash string `json:"txHash"`
-		Error  string `json:"error,omitempty"`
-		Revert string `json:"revert,omitempty"`
-	} `json:"results"`
+	BundleHash        string               `json:"bundleHash"`
+	BundleGasPrice    string               `json:"bundleGasPrice"`
+	CoinbaseDiff      string               `json:"coinbaseDiff"` // Miner Profit
+	EthSentToCoinbase string               `json:"ethSentToCoinbase"`
+	GasFees           string               `json:"gasFees"`
+	StateBlockNumber  uint64               `json:"stateBlockNumber"`
+	TotalGasUsed      uint64               `json:"totalGasUsed"`
+	Results           []callBundleTxResult `json:"results"`
+}
+
+type callBundleTxResult struct {
+	TxHash            string `json:"txHash"`
+	FromAddress       string `json:"fromAddress"`
+	ToAddress         string `json:"toAddress"`
+	GasUsed           uint64 `json:"gasUsed"`
+	GasPrice          string `json:"gasPrice"`
+	GasFees           string `json:"gasFees"`
+	CoinbaseDiff      string `json:"coinbaseDiff"`
+	EthSentToCoinbase string `json:"ethSentToCoinbase"`
+	Value             string `json:"value,omitempty"`
+	Error             string `json:"error,omitempty"`
+	Revert            string `json:"revert,omitempty"`
 }
 
 // RPC Method Params
@@ -143,14 +163,25 @@ type MevPrivacy struct {
 }
 
 // MevSimResponse captures the detailed output
+// Quantities are kept as the relay sent them, hex or decimal; Numbers parses them.
 type MevSimResponse struct {
 	Success         bool          `json:"success"`
+	Error           string        `json:"error,omitempty"`
 	StateBlock      string        `json:"stateBlock"`
 	MevGasPrice     string        `json:"mevGasPrice"`
 	Profit          string        `json:"profit"`
 	RefundableValue string        `json:"refundableValue"`
 	GasUsed         string        `json:"gasUsed"`
-	Logs            []TxLogResult `json:"logs,omitempty"` // <--- The best part
+	Logs            []TxLogResult `json:"logs,omitempty"` // <--- The best part, one entry per body item
+	// ExecError is the execution error of the transaction that failed the bundle.
+	ExecError string `json:"execError,omitempty"`
+	// Revert is the hex-encoded revert data of the transaction that failed the bundle.
+	Revert string `json:"revert,omitempty"`
+	// RevertReason is Revert decoded by Simulate with the client's ABI registry.
+	RevertReason *RevertReason `json:"-"`
+	// Raw is the response as the relay sent it, including fields this type does not know.
+	// It is nil for responses of a custom simulator.
+	Raw json.RawMessage `json:"-"`
 }
 
 // EthCancelBundleParams represents the parameters for eth_cancelBundle.
@@ -239,8 +270,19 @@ type FlashbotsGetMevRefundTotalBySenderParams struct {
 	Sender string `json:"sender"`
 }
 
+// TxLogResult is the simulation result of one body item: a transaction, or a nested bundle whose items
+// are in BundleLogs.
 type TxLogResult struct {
-	TxLogs []LogEntry `json:"txLogs,omitempty"`
+	TxLogs     []LogEntry    `json:"txLogs,omitempty"`
+	BundleLogs []TxLogResult `json:"bundleLogs,omitempty"`
+	// TxHash, GasUsed, Error and Revert are reported per transaction by the local simulator.
+	TxHash  string `json:"txHash,omitempty"`
+	GasUsed string `json:"gasUsed,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Revert is the hex-encoded revert data of a reverted transaction.
+	Revert string `json:"revert,omitempty"`
+	// RevertReason is Revert decoded by Simulate with the client's ABI registry.
+	RevertReason *RevertReason `json:"-"`
 }
 
 type LogEntry struct {